@@ -0,0 +1,387 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	junit "github.com/joshdk/go-junit"
+	"golang.org/x/exp/slog"
+)
+
+// workflowRunConclusions are the values GitHub uses for a completed workflow run's conclusion.
+var workflowRunConclusions = map[string]bool{
+	"success":         true,
+	"failure":         true,
+	"neutral":         true,
+	"cancelled":       true,
+	"skipped":         true,
+	"timed_out":       true,
+	"action_required": true,
+	"stale":           true,
+}
+
+// FetchErrorKind classifies why an SDK's results could not be fetched.
+type FetchErrorKind string
+
+const (
+	// FetchErrorNoArtifacts means the SDK has no artifacts with the configured name at all.
+	FetchErrorNoArtifacts FetchErrorKind = "no-artifacts"
+
+	// FetchErrorNoMatch means there are artifacts with the configured name, but none are unexpired and from a matching
+	// workflow run.
+	FetchErrorNoMatch FetchErrorKind = "no-matching-artifact"
+
+	// FetchErrorDownload means a request to GitHub, or reading a local artifact, failed.
+	FetchErrorDownload FetchErrorKind = "download"
+
+	// FetchErrorParse means the artifact was fetched but its test results could not be read.
+	FetchErrorParse FetchErrorKind = "parse"
+)
+
+// FetchError is returned by an ArtifactSource when an SDK's results can't be fetched.
+type FetchError struct {
+	Kind FetchErrorKind
+	Err  error
+}
+
+func (e *FetchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// Reason describes the kind of failure for display.
+func (e *FetchError) Reason() string {
+	switch e.Kind {
+	case FetchErrorNoArtifacts:
+		return "no artifacts"
+	case FetchErrorNoMatch:
+		return "no matching artifact"
+	case FetchErrorDownload:
+		return "download failed"
+	case FetchErrorParse:
+		return "results could not be parsed"
+	default:
+		return "results unavailable"
+	}
+}
+
+func newFetchError(kind FetchErrorKind, format string, args ...any) *FetchError {
+	return &FetchError{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// ArtifactSource fetches the junit results for an SDK. Errors should be a *FetchError.
+type ArtifactSource interface {
+	Fetch(ctx context.Context, sdk SDKMeta) (Artifact, error)
+}
+
+// RecentArtifactSource is an ArtifactSource that can also fetch the results of an SDK's earlier workflow runs, for
+// flaky test detection.
+type RecentArtifactSource interface {
+	ArtifactSource
+
+	// FetchRecent returns up to n of the SDK's most recent artifacts, newest first. It fails only if the newest can't be
+	// fetched.
+	FetchRecent(ctx context.Context, sdk SDKMeta, n int) ([]Artifact, error)
+}
+
+// Artifact is the junit results for an SDK along with where they came from.
+type Artifact struct {
+	Suites     []Suite
+	Provenance Provenance
+}
+
+// Suite is a test suite along with the path, within the artifact, of the file it was read from.
+type Suite struct {
+	junit.Suite
+	File string
+
+	// Platform is set if the suite came from an artifact holding a single platform's results, see Platforms.
+	Platform string
+
+	// Format is the result format the file was read as.
+	Format ResultFormat
+}
+
+// Provenance records which SDK commit and workflow run a report reflects. Fields are left empty when the source
+// doesn't know them, such as the commit of a locally saved artifact.
+type Provenance struct {
+	CommitSHA string
+	RunID     int64
+	RunURL    string
+	CreatedAt time.Time
+	Size      int64
+}
+
+func (p Provenance) ShortSHA() string {
+	if len(p.CommitSHA) > 7 {
+		return p.CommitSHA[:7]
+	}
+
+	return p.CommitSHA
+}
+
+// GitHubArtifactSource downloads the most recent matching workflow artifact from the SDK's GitHub repo.
+type GitHubArtifactSource struct {
+	GitHub *GitHub
+}
+
+func (g GitHubArtifactSource) Fetch(ctx context.Context, sdk SDKMeta) (Artifact, error) {
+	if len(sdk.Platforms.Artifacts) > 0 {
+		return fetchPlatformArtifacts(ctx, sdk, func(ctx context.Context, _, artifactName string) (Artifact, error) {
+			platformSDK := sdk
+			platformSDK.ArtifactName = artifactName
+			return g.fetch(ctx, platformSDK)
+		})
+	}
+
+	return g.fetch(ctx, sdk)
+}
+
+func (g GitHubArtifactSource) fetch(ctx context.Context, sdk SDKMeta) (Artifact, error) {
+	found, err := findArtifacts(ctx, g.GitHub, sdk, 1)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	return g.download(ctx, sdk, found[0])
+}
+
+// FetchRecent downloads the SDK's latest matching artifact, as Fetch does, followed by those of up to n-1 earlier
+// workflow runs that concluded successfully, each from a different run. Failing to find or download any but the
+// latest is logged and those runs left out.
+func (g GitHubArtifactSource) FetchRecent(ctx context.Context, sdk SDKMeta, n int) ([]Artifact, error) {
+	found, err := findArtifacts(ctx, g.GitHub, sdk, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// older runs must have succeeded, so runs that were cancelled or broke part way through, leaving partial results,
+	// aren't compared
+	successful := sdk
+	successful.Conclusion = "success"
+	older, err := findArtifacts(ctx, g.GitHub, successful, n)
+	if err != nil {
+		slog.Warn("no older successful runs", "sdk", sdk.Name, "error", err)
+	}
+	latest := found[0]
+	for _, f := range older {
+		if len(found) == n {
+			break
+		}
+		if f.run.GetID() != latest.run.GetID() && f.artifact.GetCreatedAt().Before(latest.artifact.GetCreatedAt().Time) {
+			found = append(found, f)
+		}
+	}
+
+	artifacts := make([]Artifact, 0, len(found))
+	for i, f := range found {
+		artifact, err := g.download(ctx, sdk, f)
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			slog.Warn("skipping older artifact", "sdk", sdk.Name, "run", f.run.GetHTMLURL(), "error", err)
+			continue
+		}
+		artifacts = append(artifacts, artifact)
+	}
+
+	slog.Info("fetched recent artifacts", "sdk", sdk.Name, "count", len(artifacts))
+
+	return artifacts, nil
+}
+
+func (g GitHubArtifactSource) download(ctx context.Context, sdk SDKMeta, found foundArtifact) (Artifact, error) {
+	data, err := downloadArtifact(ctx, g.GitHub, sdk, found.artifact)
+	if err != nil {
+		return Artifact{}, newFetchError(FetchErrorDownload, "error downloading artifact from %s: %v", sdk.Repo, err)
+	}
+
+	suites, err := readArtifactZip(data, sdk)
+	if err != nil {
+		return Artifact{}, newFetchError(FetchErrorParse, "error parsing artifact from %s: %v", sdk.Repo, err)
+	}
+
+	return Artifact{
+		Suites: suites,
+		Provenance: Provenance{
+			CommitSHA: found.run.GetHeadSHA(),
+			RunID:     found.run.GetID(),
+			RunURL:    found.run.GetHTMLURL(),
+			CreatedAt: found.artifact.GetCreatedAt().Time,
+			Size:      found.artifact.GetSizeInBytes(),
+		},
+	}, nil
+}
+
+type foundArtifact struct {
+	artifact *github.Artifact
+	run      *github.WorkflowRun
+}
+
+// findArtifacts returns up to n of the most recently created artifacts in the SDK's repo named sdk.ArtifactName that
+// have not expired and whose workflow run matches the SDK's branch, workflow, event and conclusion, newest first. Only
+// the newest artifact of each workflow run is returned. GitHub lists artifacts newest first, so paging stops as soon
+// as n are found. An artifact whose workflow run can't be looked up is skipped; that is only an error if nothing else
+// matches.
+func findArtifacts(ctx context.Context, gh *GitHub, sdk SDKMeta, n int) ([]foundArtifact, error) {
+	owner, repo, _ := strings.Cut(sdk.Repo, "/")
+
+	var workflowID int64
+	if sdk.Workflow != "" {
+		workflow, _, err := gh.client.Actions.GetWorkflowByFileName(ctx, owner, repo, sdk.Workflow)
+		if err != nil {
+			return nil, newFetchError(FetchErrorDownload, "error getting workflow %s: %v", sdk.Workflow, err)
+		}
+		workflowID = workflow.GetID()
+	}
+
+	var found []foundArtifact
+	var runErrs []error
+	seen := make(map[int64]bool)
+	total := 0
+	for page := 1; page != 0 && len(found) < n; {
+		// go-github's ListArtifacts can't filter by name, so the request is built by hand
+		u := fmt.Sprintf("repos/%s/%s/actions/artifacts?name=%s&per_page=100&page=%d", owner, repo, url.QueryEscape(sdk.ArtifactName), page)
+		req, err := gh.client.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, newFetchError(FetchErrorDownload, "error listing artifacts: %v", err)
+		}
+
+		var artifacts github.ArtifactList
+		resp, err := gh.client.Do(ctx, req, &artifacts)
+		if err != nil {
+			return nil, newFetchError(FetchErrorDownload, "error listing artifacts: %v", err)
+		}
+		page = resp.NextPage
+		total += len(artifacts.Artifacts)
+
+		// newest first within the page too, in case the API's order ever changes
+		candidates := artifacts.Artifacts
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].GetCreatedAt().After(candidates[j].GetCreatedAt().Time)
+		})
+
+		for _, a := range candidates {
+			if a.GetName() != sdk.ArtifactName || a.GetExpired() || a.GetWorkflowRun().GetHeadBranch() != sdk.Branch {
+				continue
+			}
+
+			runID := a.GetWorkflowRun().GetID()
+			if seen[runID] {
+				continue
+			}
+			seen[runID] = true
+
+			run, err := gh.getWorkflowRun(ctx, owner, repo, runID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, newFetchError(FetchErrorDownload, "error getting workflow run %d: %v", runID, err)
+				}
+				// one run that can't be looked up shouldn't hide the others
+				slog.Warn("skipping artifact, error getting its workflow run", "sdk", sdk.Name, "artifact", a.GetID(), "run", runID, "error", err)
+				runErrs = append(runErrs, fmt.Errorf("run %d: %v", runID, err))
+				continue
+			}
+
+			if workflowID != 0 && run.GetWorkflowID() != workflowID {
+				continue
+			}
+			if sdk.Event != "" && run.GetEvent() != sdk.Event {
+				continue
+			}
+			if sdk.Conclusion != "" && run.GetConclusion() != sdk.Conclusion {
+				continue
+			}
+
+			slog.Info("selected artifact", "sdk", sdk.Name, "artifact", a.GetID(), "created", a.GetCreatedAt(), "run", run.GetHTMLURL(), "commit", run.GetHeadSHA())
+			found = append(found, foundArtifact{artifact: a, run: run})
+			if len(found) == n {
+				break
+			}
+		}
+	}
+
+	if len(found) > 0 {
+		return found, nil
+	}
+
+	if len(runErrs) > 0 {
+		return nil, newFetchError(FetchErrorDownload, "error getting workflow runs: %v", errors.Join(runErrs...))
+	}
+
+	if total == 0 {
+		return nil, newFetchError(FetchErrorNoArtifacts, "no artifacts named %s found in %s", sdk.ArtifactName, sdk.Repo)
+	}
+
+	return nil, newFetchError(FetchErrorNoMatch, "none of the %d artifacts named %s are unexpired and from a run matching branch=%s workflow=%s event=%s conclusion=%s",
+		total, sdk.ArtifactName, sdk.Branch, orAny(sdk.Workflow), orAny(sdk.Event), orAny(sdk.Conclusion))
+}
+
+func orAny(s string) string {
+	if s == "" {
+		return "any"
+	}
+
+	return s
+}
+
+// DirArtifactSource reads previously saved artifacts from a local directory. For each SDK it looks for either
+// <sdk-name>.zip, as downloaded from GitHub, or a <sdk-name> directory containing the unpacked test result files. SDKs
+// with an artifact per platform are read from <sdk-name>-<platform>.zip or directory instead. A file's modification
+// time says when it was copied, not when the tests ran, so local artifacts have no CreatedAt and are never stale.
+type DirArtifactSource struct {
+	Dir string
+}
+
+func (d DirArtifactSource) Fetch(ctx context.Context, sdk SDKMeta) (Artifact, error) {
+	if len(sdk.Platforms.Artifacts) > 0 {
+		return fetchPlatformArtifacts(ctx, sdk, func(_ context.Context, platform, _ string) (Artifact, error) {
+			return d.fetch(sdk, sdk.Name+"-"+platform)
+		})
+	}
+
+	return d.fetch(sdk, sdk.Name)
+}
+
+func (d DirArtifactSource) fetch(sdk SDKMeta, name string) (Artifact, error) {
+	zipPath := filepath.Join(d.Dir, name+".zip")
+	data, err := os.ReadFile(zipPath)
+	if err == nil {
+		slog.Info("reading local artifact", "sdk", sdk.Name, "file", zipPath)
+		suites, err := readArtifactZip(data, sdk)
+		if err != nil {
+			return Artifact{}, newFetchError(FetchErrorParse, "error parsing artifact %s: %v", zipPath, err)
+		}
+
+		return Artifact{Suites: suites, Provenance: Provenance{Size: int64(len(data))}}, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return Artifact{}, newFetchError(FetchErrorDownload, "error reading artifact %s: %v", zipPath, err)
+	}
+
+	dirPath := filepath.Join(d.Dir, name)
+	info, err := os.Stat(dirPath)
+	if err != nil || !info.IsDir() {
+		return Artifact{}, newFetchError(FetchErrorNoArtifacts, "no artifact found for %s: expected %s or directory %s", sdk.Name, zipPath, dirPath)
+	}
+
+	slog.Info("reading local artifact", "sdk", sdk.Name, "dir", dirPath)
+	suites, err := readResultsDir(dirPath, sdk)
+	if err != nil {
+		return Artifact{}, newFetchError(FetchErrorParse, "error parsing test results in %s: %v", dirPath, err)
+	}
+
+	return Artifact{Suites: suites}, nil
+}
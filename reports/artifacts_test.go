@@ -0,0 +1,221 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFindArtifactsStopsEarly(t *testing.T) {
+	const pages = 3
+
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/repos/TBD54566975/web5-rs/actions/artifacts":
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			if page < pages {
+				w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d>; rel="next"`, "http://"+r.Host, r.URL.Path, page+1))
+			}
+
+			// two runs per page, newest first
+			var artifacts []map[string]any
+			for i := 0; i < 2; i++ {
+				run := (page-1)*2 + i + 1
+				artifacts = append(artifacts, map[string]any{
+					"id":           run,
+					"name":         "kotlin-test-results",
+					"created_at":   time.Date(2024, 1, 30-run, 0, 0, 0, 0, time.UTC),
+					"workflow_run": map[string]any{"id": run, "head_branch": "main"},
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"total_count": pages * 2, "artifacts": artifacts})
+		case strings.HasPrefix(r.URL.Path, "/repos/TBD54566975/web5-rs/actions/runs/"):
+			id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/repos/TBD54566975/web5-rs/actions/runs/"))
+			json.NewEncoder(w).Encode(map[string]any{"id": id, "head_sha": fmt.Sprintf("sha%d", id), "conclusion": "success"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gh, err := NewGitHub(GitHubConfig{BaseURL: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sdk := NewSDKMeta("web5-core-kt", "TBD54566975/web5-rs", "kotlin-test-results", "", "web5", nil, nil)
+	for _, name := range []string{"web5-core-kt", "web5-rs"} {
+		sdk.Name = name
+		found, err := findArtifacts(context.Background(), gh, sdk, 2)
+		if err != nil {
+			t.Fatalf("%s: error finding artifacts: %v", name, err)
+		}
+		if len(found) != 2 || found[0].run.GetID() != 1 || found[1].run.GetID() != 2 {
+			t.Fatalf("%s: got %d artifacts, want runs 1 and 2", name, len(found))
+		}
+	}
+
+	if got := requests["/repos/TBD54566975/web5-rs/actions/artifacts"]; got != 2 {
+		t.Errorf("listed artifacts %d times, want once per sdk, as the first page has enough", got)
+	}
+	for _, run := range []string{"1", "2"} {
+		if got := requests["/repos/TBD54566975/web5-rs/actions/runs/"+run]; got != 1 {
+			t.Errorf("got run %s %d times, want once across both sdks", run, got)
+		}
+	}
+}
+
+func TestFindArtifactsSkipsFailedRuns(t *testing.T) {
+	failing := map[string]bool{"1": true}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/TBD54566975/web5-rs/actions/artifacts":
+			var artifacts []map[string]any
+			for run := 1; run <= 2; run++ {
+				artifacts = append(artifacts, map[string]any{
+					"id":           run,
+					"name":         "rust-test-results",
+					"created_at":   time.Date(2024, 1, 30-run, 0, 0, 0, 0, time.UTC),
+					"workflow_run": map[string]any{"id": run, "head_branch": "main"},
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"total_count": 2, "artifacts": artifacts})
+		case strings.HasPrefix(r.URL.Path, "/repos/TBD54566975/web5-rs/actions/runs/"):
+			id := strings.TrimPrefix(r.URL.Path, "/repos/TBD54566975/web5-rs/actions/runs/")
+			if failing[id] {
+				http.Error(w, "boom", http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprintf(w, `{"id": %s, "head_sha": "sha%s"}`, id, id)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gh, err := NewGitHub(GitHubConfig{BaseURL: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdk := NewSDKMeta("web5-rs", "TBD54566975/web5-rs", "rust-test-results", "", "web5", nil, nil)
+
+	found, err := findArtifacts(context.Background(), gh, sdk, 1)
+	if err != nil {
+		t.Fatalf("error finding artifacts: %v", err)
+	}
+	if len(found) != 1 || found[0].run.GetID() != 2 {
+		t.Fatalf("got %d artifacts, want the one from run 2", len(found))
+	}
+
+	// with every run failing, there's nothing to return
+	failing["2"] = true
+	gh, err = NewGitHub(GitHubConfig{BaseURL: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = findArtifacts(context.Background(), gh, sdk, 1)
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Kind != FetchErrorDownload {
+		t.Errorf("got error %v, want a %s error", err, FetchErrorDownload)
+	}
+}
+
+func TestDirArtifactSourceHasNoCreatedAt(t *testing.T) {
+	dir := t.TempDir()
+	result := `<testsuites><testsuite name="Web5TestVectorsDidJwk"><testcase name="resolve"/></testsuite></testsuites>`
+	writeTestFile(t, dir, "web5-js.zip", string(zipBytes(t, []testFile{{"TEST-DidJwk.xml", result}})))
+	writeTestFile(t, dir, "web5-kt/TEST-DidJwk.xml", result)
+
+	// however old the files are, that's not when the tests ran
+	old := time.Now().AddDate(-1, 0, 0)
+	for _, name := range []string{"web5-js.zip", "web5-kt"} {
+		if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source := DirArtifactSource{Dir: dir}
+	for _, name := range []string{"web5-js", "web5-kt"} {
+		artifact, err := source.Fetch(context.Background(), NewSDKMeta(name, "TBD54566975/"+name, "junit-results", "", "web5", nil, nil))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(artifact.Suites) != 1 {
+			t.Errorf("%s: got %d suites, want 1", name, len(artifact.Suites))
+		}
+		if !artifact.Provenance.CreatedAt.IsZero() {
+			t.Errorf("%s: got CreatedAt %s, want it unset", name, artifact.Provenance.CreatedAt)
+		}
+	}
+}
+
+func TestFetchRecentUsesSuccessfulOlderRuns(t *testing.T) {
+	// newest first: the latest run failed, as do runs with failing tests, and run 3 was cancelled
+	conclusions := []string{"failure", "success", "cancelled", "success", "success"}
+	result := zipBytes(t, []testFile{{"TEST-DidJwk.xml", `<testsuites><testsuite name="Web5TestVectorsDidJwk"><testcase name="resolve"/></testsuite></testsuites>`}})
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "/repos/TBD54566975/web5-kt/actions/"
+		switch {
+		case r.URL.Path == prefix+"artifacts":
+			var artifacts []map[string]any
+			for i := range conclusions {
+				run := i + 1
+				artifacts = append(artifacts, map[string]any{
+					"id":           run,
+					"name":         "junit-results",
+					"created_at":   time.Date(2024, 1, 30-run, 0, 0, 0, 0, time.UTC),
+					"workflow_run": map[string]any{"id": run, "head_branch": "main"},
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"total_count": len(artifacts), "artifacts": artifacts})
+		case strings.HasPrefix(r.URL.Path, prefix+"runs/"):
+			run, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, prefix+"runs/"))
+			json.NewEncoder(w).Encode(map[string]any{"id": run, "head_sha": fmt.Sprintf("sha%d", run), "conclusion": conclusions[run-1]})
+		case strings.HasPrefix(r.URL.Path, prefix+"artifacts/"):
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, prefix+"artifacts/"), "/zip")
+			http.Redirect(w, r, server.URL+"/blobs/"+id, http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/blobs/"):
+			w.Write(result)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gh, err := NewGitHub(GitHubConfig{BaseURL: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sdk := NewSDKMeta("web5-kt", "TBD54566975/web5-kt", "junit-results", "", "web5", nil, nil)
+	artifacts, err := GitHubArtifactSource{GitHub: gh}.FetchRecent(context.Background(), sdk, 3)
+	if err != nil {
+		t.Fatalf("error fetching recent artifacts: %v", err)
+	}
+
+	var runs []int64
+	for _, a := range artifacts {
+		runs = append(runs, a.Provenance.RunID)
+	}
+	if fmt.Sprint(runs) != "[1 2 4]" {
+		t.Errorf("got runs %v, want the latest, 1, then successful runs 2 and 4", runs)
+	}
+}
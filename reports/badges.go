@@ -5,13 +5,18 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	badge "github.com/essentialkaos/go-badge"
 	"golang.org/x/exp/slog"
 )
 
 var (
-	badgeGenerator *badge.Generator
+	// badgeGenerator is created by getBadgeGenerator the first time a badge is rendered, so the package can be used
+	// without the font, such as in tests or tools that don't render badges.
+	badgeGenerator     *badge.Generator
+	badgeGeneratorErr  error
+	badgeGeneratorOnce sync.Once
 
 	fonts = []string{
 		"/System/Library/Fonts/Supplemental/Verdana.ttf",   // OSX
@@ -19,37 +24,54 @@ var (
 	}
 )
 
-func init() {
-	var err error
+func getBadgeGenerator() (*badge.Generator, error) {
+	badgeGeneratorOnce.Do(func() {
+		fontPath := ""
+		for _, potentialFontPath := range fonts {
+			if _, err := os.Stat(potentialFontPath); err != nil {
+				continue
+			}
+			fontPath = potentialFontPath
+		}
+		if fontPath == "" {
+			badgeGeneratorErr = fmt.Errorf("font not found in any of:\n%s", strings.Join(fonts, "\n"))
+			return
+		}
 
-	fontPath := ""
-	for _, potentialFontPath := range fonts {
-		if _, err := os.Stat(potentialFontPath); err != nil {
-			continue
+		badgeGenerator, badgeGeneratorErr = badge.NewGenerator(fontPath, 11)
+		if badgeGeneratorErr != nil {
+			badgeGeneratorErr = fmt.Errorf("error loading badge font %s: %v", fontPath, badgeGeneratorErr)
 		}
-		fontPath = potentialFontPath
-	}
-	if fontPath == "" {
-		panic(fmt.Sprintf("Font not found in any of:\n%s", strings.Join(fonts, "\n")))
-	}
+	})
 
-	badgeGenerator, err = badge.NewGenerator(fontPath, 11)
-	if err != nil {
-		panic(err)
-	}
+	return badgeGenerator, badgeGeneratorErr
 }
 
 type Badge struct {
-	Name    string
-	Error   bool
+	Name  string
+	Error bool
+	Stale bool
+
+	// Unavailable is set if the SDK's results could not be fetched.
+	Unavailable bool
+
 	Passing int
 	Total   int
 }
 
 func (b Badge) Render(dir string) error {
+	generator, err := getBadgeGenerator()
+	if err != nil {
+		return err
+	}
+
 	color := badge.COLOR_BRIGHTGREEN
-	if b.Error {
+	if b.Unavailable {
+		color = badge.COLOR_LIGHTGREY
+	} else if b.Error {
 		color = badge.COLOR_RED
+	} else if b.Stale {
+		color = badge.COLOR_YELLOW
 	}
 
 	filename := filepath.Join(dir, fmt.Sprintf("%s.svg", b.Name))
@@ -61,7 +83,12 @@ func (b Badge) Render(dir string) error {
 	defer f.Close()
 
 	text := fmt.Sprintf("%d/%d", b.Passing, b.Total)
-	badgeBytes := badgeGenerator.GenerateFlat("spec compliance", text, color)
+	if b.Unavailable {
+		text = "unavailable"
+	} else if b.Stale {
+		text += " (stale)"
+	}
+	badgeBytes := generator.GenerateFlat("spec compliance", text, color)
 
 	if _, err := f.Write(badgeBytes); err != nil {
 		return err
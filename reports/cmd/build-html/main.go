@@ -1,18 +1,152 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"golang.org/x/exp/slog"
 
 	"github.com/TBD54566975/sdk-development/reports"
 )
 
+var (
+	vectorConfigPath = flag.String("vectors", "", "path to a JSON file listing the vector suites to test against. Defaults to the built-in list (reports/vectors.json)")
+	sdkConfigPath    = flag.String("sdks", "", "path to a JSON file listing the SDKs to report on. Defaults to the built-in list (reports/sdks.json)")
+	workers          = flag.Int("workers", reports.DefaultWorkers, "number of SDKs to fetch and parse at once")
+	artifactDir      = flag.String("artifacts", "", "read artifacts from this directory (<sdk-name>.zip or an unpacked <sdk-name> directory) instead of downloading them from GitHub")
+	writeJSON        = flag.Bool("json", false, "also write the report as JSON to _site/report.json")
+	historyPath      = flag.String("history", "", "append this run to the JSON lines history file at this path and render trends from it")
+
+	pins     suiteRefs
+	releases suiteRefs
+)
+
+func init() {
+	flag.Var(&pins, "pin", "test against `suite=ref`, a spec release tag or commit, instead of the checked out vectors. May be repeated")
+	flag.Var(&releases, "release", "also check every SDK against `suite=ref`, a spec release tag or commit, for the release compliance table. May be repeated")
+}
+
+// suiteRefs is a repeatable suite=ref flag.
+type suiteRefs []suiteRef
+
+type suiteRef struct {
+	suite, ref string
+}
+
+func (s *suiteRefs) String() string {
+	var refs []string
+	for _, r := range *s {
+		refs = append(refs, r.suite+"="+r.ref)
+	}
+	return strings.Join(refs, ",")
+}
+
+func (s *suiteRefs) Set(value string) error {
+	suite, ref, ok := strings.Cut(value, "=")
+	if !ok || suite == "" || ref == "" {
+		return fmt.Errorf("expected suite=ref, got %q", value)
+	}
+	*s = append(*s, suiteRef{suite: suite, ref: ref})
+	return nil
+}
+
+// cleanups remove the vectors downloaded for -pin and -release.
+var cleanups []func()
+
+func cleanup() {
+	for _, c := range cleanups {
+		c()
+	}
+}
+
 func main() {
-	allReports, err := reports.GetAllReports()
+	flag.Parse()
+	defer cleanup()
+
+	suites, err := reports.LoadVectorSuites(*vectorConfigPath)
+	if err != nil {
+		slog.Error("error loading vector suite config")
+		panic(err)
+	}
+	reports.VectorSuites = suites
+
+	ctx := context.Background()
+
+	var gh *reports.GitHub
+	if *artifactDir == "" {
+		gh = newGitHub()
+	} else if len(pins) > 0 || len(releases) > 0 {
+		// only needed to fetch refs that aren't in a local checkout of the vectors
+		if config, err := reports.GitHubConfigFromEnv(); err == nil {
+			if gh, err = reports.NewGitHub(config); err != nil {
+				slog.Error("error creating github client")
+				panic(err)
+			}
+		}
+	}
+
+	for _, pin := range pins {
+		pinned, err := pinVectorSuite(ctx, gh, pin)
+		if err != nil {
+			slog.Error("error pinning vector suite", "suite", pin.suite, "ref", pin.ref)
+			panic(err)
+		}
+		for i := range reports.VectorSuites {
+			if reports.VectorSuites[i].Name == pinned.Name {
+				reports.VectorSuites[i] = pinned
+			}
+		}
+	}
+
+	if err := reports.CheckVectorSuites(); err != nil {
+		// each problem on its own line, without a stack trace, so they're easy to read in CI logs
+		slog.Error("error reading test vectors")
+		fmt.Fprintln(os.Stderr, err)
+		cleanup()
+		os.Exit(1)
+	}
+
+	sdks, err := reports.LoadSDKs(*sdkConfigPath)
 	if err != nil {
-		slog.Error("error downloading/parsing reports")
+		slog.Error("error loading sdk config")
+		panic(err)
+	}
+
+	var source reports.ArtifactSource
+	if *artifactDir != "" {
+		slog.Info("reading artifacts from local directory", "dir", *artifactDir)
+		source = reports.DirArtifactSource{Dir: *artifactDir}
+	} else {
+		if err := reports.CheckSubmoduleStatus(ctx, gh, sdks); err != nil {
+			slog.Error("error checking submodule status", "error", err)
+		}
+		source = reports.GitHubArtifactSource{GitHub: gh}
+	}
+
+	allReports, err := reports.GetAllReports(ctx, sdks, source, *workers)
+	if err != nil {
+		// SDKs that failed are left out of the report, the rest are still worth publishing
+		slog.Error("error downloading/parsing some reports", "error", err)
+	}
+
+	var releaseSuites []reports.VectorSuite
+	for _, release := range releases {
+		pinned, err := pinVectorSuite(ctx, gh, release)
+		if err != nil {
+			slog.Error("error pinning vector suite", "suite", release.suite, "ref", release.ref)
+			panic(err)
+		}
+		releaseSuites = append(releaseSuites, pinned)
+	}
+
+	compliance, err := reports.ComplianceMatrix(allReports, releaseSuites)
+	if err != nil {
+		slog.Error("error checking release compliance")
 		panic(err)
 	}
 
@@ -21,9 +155,63 @@ func main() {
 		panic(err)
 	}
 
-	err = reports.WriteHTML(allReports, "_site")
+	var history []reports.HistoryEntry
+	if *historyPath != "" {
+		history, err = reports.ReadHistory(*historyPath)
+		if err != nil {
+			slog.Error("error reading history")
+			panic(err)
+		}
+
+		entry := reports.NewHistoryEntry(allReports, time.Now())
+		if err := reports.AppendHistory(*historyPath, entry); err != nil {
+			slog.Error("error recording history")
+			panic(err)
+		}
+		history = append(history, entry)
+	}
+
+	err = reports.WriteHTML(allReports, history, compliance, "_site")
 	if err != nil {
 		slog.Error("error writing html output")
 		panic(err)
 	}
+
+	if *writeJSON {
+		if err := reports.WriteJSON(allReports, compliance, "_site"); err != nil {
+			slog.Error("error writing json output")
+			panic(err)
+		}
+	}
+}
+
+func pinVectorSuite(ctx context.Context, gh *reports.GitHub, pin suiteRef) (reports.VectorSuite, error) {
+	suite, ok := reports.FindVectorSuite(pin.suite)
+	if !ok {
+		return reports.VectorSuite{}, fmt.Errorf("unknown vector suite %q", pin.suite)
+	}
+
+	pinned, remove, err := reports.PinVectorSuite(ctx, gh, suite, pin.ref)
+	if err != nil {
+		return reports.VectorSuite{}, err
+	}
+	cleanups = append(cleanups, remove)
+
+	return pinned, nil
+}
+
+func newGitHub() *reports.GitHub {
+	config, err := reports.GitHubConfigFromEnv()
+	if err != nil {
+		slog.Error("error reading github credentials. Set GITHUB_TOKEN, or use -artifacts to build from local artifacts")
+		panic(err)
+	}
+
+	gh, err := reports.NewGitHub(config)
+	if err != nil {
+		slog.Error("error creating github client")
+		panic(err)
+	}
+
+	return gh
 }
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/TBD54566975/sdk-development/reports"
+)
+
+var (
+	vectorConfigPath = flag.String("vectors", "", "path to a JSON file listing the vector suites to test against. Defaults to the built-in list (reports/vectors.json)")
+	sdkConfigPath    = flag.String("sdks", "", "path to a JSON file listing the SDKs to report on, for a live run. Defaults to the built-in list (reports/sdks.json)")
+	workers          = flag.Int("workers", reports.DefaultWorkers, "number of SDKs to fetch and parse at once")
+	artifactDir      = flag.String("artifacts", "", "for a live run, read artifacts from this directory instead of downloading them from GitHub")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <before report.json> [<after report.json>]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Compares two saved reports (written by build-html -json), or a saved report and a live run if only one is given.")
+		fmt.Fprintln(flag.CommandLine.Output(), "Exits with status 1 if any vector regressed, or an SDK's results could no longer be fetched.")
+		fmt.Fprintln(flag.CommandLine.Output())
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 || flag.NArg() > 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	before, err := reports.ReadJSONReport(flag.Arg(0))
+	if err != nil {
+		slog.Error("error reading before report")
+		panic(err)
+	}
+
+	var after reports.HistoryEntry
+	if flag.NArg() == 2 {
+		afterReport, err := reports.ReadJSONReport(flag.Arg(1))
+		if err != nil {
+			slog.Error("error reading after report")
+			panic(err)
+		}
+		after = afterReport.HistoryEntry()
+	} else {
+		after = liveRun()
+	}
+
+	regressions, unavailable := 0, 0
+	for _, diff := range reports.Diff(before.HistoryEntry(), after) {
+		if diff.IsEmpty() {
+			continue
+		}
+
+		fmt.Println(diff.SDK)
+		if diff.Unavailable {
+			fmt.Println("  unavailable  results could not be fetched, vectors not compared")
+			unavailable++
+		}
+		if diff.Recovered {
+			fmt.Println("  recovered  results could be fetched again, vectors not compared")
+		}
+		printChanges("regressed", diff.Regressed)
+		printChanges("fixed", diff.Fixed)
+		printChanges("added", diff.Added)
+		printChanges("removed", diff.Removed)
+		regressions += len(diff.Regressed)
+	}
+
+	fmt.Printf("%d regressions, %d sdks unavailable\n", regressions, unavailable)
+	if regressions > 0 || unavailable > 0 {
+		os.Exit(1)
+	}
+}
+
+func printChanges(kind string, changes []reports.VectorChange) {
+	for _, c := range changes {
+		fmt.Printf("  %-9s  %s/%s  %s -> %s\n", kind, c.Feature, c.Vector, orNone(c.Before), orNone(c.After))
+	}
+}
+
+func orNone(s reports.Status) string {
+	if s == "" {
+		return "(none)"
+	}
+
+	return string(s)
+}
+
+func liveRun() reports.HistoryEntry {
+	suites, err := reports.LoadVectorSuites(*vectorConfigPath)
+	if err != nil {
+		slog.Error("error loading vector suite config")
+		panic(err)
+	}
+	reports.VectorSuites = suites
+
+	if err := reports.CheckVectorSuites(); err != nil {
+		// each problem on its own line, without a stack trace, so they're easy to read in CI logs
+		slog.Error("error reading test vectors")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	sdks, err := reports.LoadSDKs(*sdkConfigPath)
+	if err != nil {
+		slog.Error("error loading sdk config")
+		panic(err)
+	}
+
+	var source reports.ArtifactSource
+	if *artifactDir != "" {
+		source = reports.DirArtifactSource{Dir: *artifactDir}
+	} else {
+		config, err := reports.GitHubConfigFromEnv()
+		if err != nil {
+			slog.Error("error reading github credentials. Set GITHUB_TOKEN, or use -artifacts to diff against local artifacts")
+			panic(err)
+		}
+
+		gh, err := reports.NewGitHub(config)
+		if err != nil {
+			slog.Error("error creating github client")
+			panic(err)
+		}
+		source = reports.GitHubArtifactSource{GitHub: gh}
+	}
+
+	allReports, err := reports.GetAllReports(context.Background(), sdks, source, *workers)
+	entry := reports.NewHistoryEntry(allReports, time.Now())
+	if err != nil {
+		slog.Error("error downloading/parsing some reports", "error", err)
+
+		// SDKs whose report couldn't be built at all are missing from allReports; count them as unavailable rather
+		// than removed, so they fail the comparison
+		for _, name := range reports.FailedSDKs(err) {
+			if !entry.IsUnavailable(name) {
+				entry.Unavailable = append(entry.Unavailable, name)
+			}
+		}
+	}
+
+	return entry
+}
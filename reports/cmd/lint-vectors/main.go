@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/TBD54566975/sdk-development/reports"
+)
+
+var (
+	vectorConfigPath = flag.String("vectors", "", "path to a JSON file listing the vector suites to lint. Defaults to the built-in list (reports/vectors.json)")
+	suiteName        = flag.String("suite", "", "only lint the vector suite with this name")
+)
+
+func main() {
+	flag.Parse()
+
+	suites, err := reports.LoadVectorSuites(*vectorConfigPath)
+	if err != nil {
+		slog.Error("error loading vector suite config")
+		panic(err)
+	}
+
+	failed := false
+	found := false
+	for _, suite := range suites {
+		if *suiteName != "" && suite.Name != *suiteName {
+			continue
+		}
+		found = true
+
+		results, err := reports.LintVectors(suite)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			failed = true
+			continue
+		}
+
+		for _, result := range results {
+			fmt.Printf("%s: %s\n", result.Suite, result.Path)
+			for _, problem := range result.Problems {
+				fmt.Printf("  - %s\n", problem)
+			}
+		}
+
+		if len(results) > 0 {
+			failed = true
+		}
+		slog.Info("linted vector suite", "suite", suite.Name, "files_with_problems", len(results))
+	}
+
+	if !found {
+		slog.Error("unknown vector suite", "suite", *suiteName)
+		os.Exit(2)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
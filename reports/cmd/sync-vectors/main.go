@@ -1,21 +1,44 @@
 package main
 
 import (
+	"flag"
 	"os"
 
 	"github.com/TBD54566975/sdk-development/reports"
 	"golang.org/x/exp/slog"
 )
 
+var sdkConfigPath = flag.String("sdks", "", "path to a JSON file listing the SDKs to sync. Defaults to the built-in list (reports/sdks.json)")
+
 func main() {
+	flag.Parse()
+
+	sdks, err := reports.LoadSDKs(*sdkConfigPath)
+	if err != nil {
+		slog.Error("error loading sdk config")
+		panic(err)
+	}
+
+	config, err := reports.GitHubConfigFromEnv()
+	if err != nil {
+		slog.Error("error reading github app credentials")
+		panic(err)
+	}
+
+	gh, err := reports.NewGitHub(config)
+	if err != nil {
+		slog.Error("error creating github client")
+		panic(err)
+	}
+
 	defer reports.CleanupGitAuth()
-	if err := reports.ConfigureGitAuth(); err != nil {
+	if err := reports.ConfigureGitAuth(gh); err != nil {
 		panic(err)
 	}
 
 	errs := make(map[string]error)
-	for _, sdk := range reports.SDKs {
-		if err := reports.SyncSDK(sdk); err != nil {
+	for _, sdk := range sdks {
+		if err := reports.SyncSDK(gh, sdk); err != nil {
 			errs[sdk.Name] = err
 		}
 	}
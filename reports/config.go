@@ -0,0 +1,256 @@
+package reports
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBranch = "main"
+
+	// defaultMaxAge is how old an SDK's artifact may be before its report is considered stale, unless the SDK config
+	// says otherwise.
+	defaultMaxAge = 14 * 24 * time.Hour
+)
+
+//go:embed sdks.json
+var defaultSDKConfig []byte
+
+// sdkConfigFile is the on-disk format of the SDK registry. See sdks.json for the default list.
+type sdkConfigFile struct {
+	SDKs []sdkConfig `json:"sdks"`
+}
+
+type sdkConfig struct {
+	Name         string          `json:"name"`
+	Repo         string          `json:"repo"`
+	ArtifactName string          `json:"artifactName"`
+	VectorPath   string          `json:"vectorPath"`
+	Type         string          `json:"type"`
+	FeatureRegex string          `json:"featureRegex"`
+	VectorRegex  string          `json:"vectorRegex"`
+	Branch       string          `json:"branch,omitempty"`
+	Workflow     string          `json:"workflow,omitempty"`
+	Event        string          `json:"event,omitempty"`
+	Conclusion   string          `json:"conclusion,omitempty"`
+	MaxAge       string          `json:"maxAge,omitempty"`
+	Format       string          `json:"format,omitempty"`
+	Include      []string        `json:"include,omitempty"`
+	Exclude      []string        `json:"exclude,omitempty"`
+	Merge        string          `json:"merge,omitempty"`
+	Platforms    *platformConfig `json:"platforms,omitempty"`
+	FlakyRuns    int             `json:"flakyRuns,omitempty"`
+	Mapper       *mapperConfig   `json:"mapper,omitempty"`
+}
+
+// LoadSDKs reads the SDK registry from the file at path. If path is empty, the default registry embedded from sdks.json
+// is used.
+func LoadSDKs(path string) ([]SDKMeta, error) {
+	if path == "" {
+		return ParseSDKs(bytes.NewReader(defaultSDKConfig))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening sdk config: %v", err)
+	}
+	defer f.Close()
+
+	sdks, err := ParseSDKs(f)
+	if err != nil {
+		return nil, fmt.Errorf("error loading sdk config from %s: %w", path, err)
+	}
+
+	return sdks, nil
+}
+
+// ParseSDKs decodes and validates an SDK registry. Every invalid entry is reported, not just the first one.
+func ParseSDKs(r io.Reader) ([]SDKMeta, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var config sdkConfigFile
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("error parsing sdk config: %v", err)
+	}
+
+	if len(config.SDKs) == 0 {
+		return nil, errors.New("sdk config does not list any sdks")
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	sdks := make([]SDKMeta, 0, len(config.SDKs))
+	for i, c := range config.SDKs {
+		sdk, err := c.toSDKMeta()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sdks[%d] (%s): %w", i, c.Name, err))
+			continue
+		}
+
+		if seen[sdk.Name] {
+			errs = append(errs, fmt.Errorf("sdks[%d] (%s): duplicate sdk name", i, c.Name))
+			continue
+		}
+		seen[sdk.Name] = true
+
+		sdks = append(sdks, sdk)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return sdks, nil
+}
+
+func (c sdkConfig) toSDKMeta() (SDKMeta, error) {
+	var errs []error
+
+	required := []struct{ field, value string }{
+		{"name", c.Name},
+		{"repo", c.Repo},
+		{"artifactName", c.ArtifactName},
+		{"vectorPath", c.VectorPath},
+		{"type", c.Type},
+	}
+	if c.Mapper.usesRegexes() {
+		required = append(required,
+			struct{ field, value string }{"featureRegex", c.FeatureRegex},
+			struct{ field, value string }{"vectorRegex", c.VectorRegex},
+		)
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", r.field))
+		}
+	}
+
+	if owner, repo, ok := strings.Cut(c.Repo, "/"); c.Repo != "" && (!ok || owner == "" || repo == "" || strings.Contains(repo, "/")) {
+		errs = append(errs, fmt.Errorf("repo %q must be in the form owner/name", c.Repo))
+	}
+
+	if c.Conclusion != "" && !workflowRunConclusions[c.Conclusion] {
+		errs = append(errs, fmt.Errorf("unknown workflow run conclusion %q", c.Conclusion))
+	}
+
+	maxAge, err := parseMaxAge(c.MaxAge)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	format, err := parseResultFormat(c.Format)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	merge, err := parseMergePolicy(c.Merge)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.FlakyRuns < 0 {
+		errs = append(errs, fmt.Errorf("flakyRuns must not be negative"))
+	}
+
+	platforms, err := c.Platforms.build()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, pattern := range append(append([]string{}, c.Include...), c.Exclude...) {
+		if err := validateGlob(pattern); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if _, ok := FindVectorSuite(c.Type); c.Type != "" && !ok {
+		errs = append(errs, fmt.Errorf("unknown type %q, it must be the name of a vector suite", c.Type))
+	}
+
+	featureRegex, err := compileConfigRegex("featureRegex", c.FeatureRegex)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	vectorRegex, err := compileConfigRegex("vectorRegex", c.VectorRegex)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	mapper, err := c.Mapper.build(featureRegex, vectorRegex)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		problems := make([]string, len(errs))
+		for i, err := range errs {
+			problems[i] = err.Error()
+		}
+		return SDKMeta{}, errors.New(strings.Join(problems, "; "))
+	}
+
+	sdk := NewSDKMeta(c.Name, c.Repo, c.ArtifactName, c.VectorPath, c.Type, featureRegex, vectorRegex)
+	if c.Branch != "" {
+		sdk.Branch = c.Branch
+	}
+	sdk.Workflow = c.Workflow
+	sdk.Event = c.Event
+	sdk.Conclusion = c.Conclusion
+	sdk.MaxAge = maxAge
+	sdk.Format = format
+	sdk.Include = c.Include
+	sdk.Exclude = c.Exclude
+	sdk.Merge = merge
+	sdk.Platforms = platforms
+	sdk.FlakyRuns = c.FlakyRuns
+	sdk.Mapper = mapper
+
+	return sdk, nil
+}
+
+func compileConfigRegex(field, expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", field, err)
+	}
+
+	return re, nil
+}
+
+// parseMaxAge parses a Go duration such as "72h", or a number of days such as "14d". "0" disables the staleness check
+// and an empty string gives the default.
+func parseMaxAge(s string) (time.Duration, error) {
+	if s == "" {
+		return defaultMaxAge, nil
+	}
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid maxAge %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid maxAge %q", s)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,145 @@
+package reports
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSDKs(t *testing.T) {
+	const valid = `{"name": "web5-js", "repo": "TBD54566975/web5-js", "artifactName": "junit-results", "vectorPath": "test-vectors", "type": "web5", "featureRegex": "Web5TestVectors(\\w+)", "vectorRegex": "(\\w+)"}`
+
+	tests := []struct {
+		name   string
+		config string
+
+		// wantErrs are substrings of the error, which must list every one of them
+		wantErrs []string
+	}{
+		{
+			name:   "valid",
+			config: `{"sdks": [` + valid + `]}`,
+		},
+		{
+			name:     "unknown field",
+			config:   `{"sdks": [{"name": "web5-js", "artifact": "junit-results"}]}`,
+			wantErrs: []string{`unknown field "artifact"`},
+		},
+		{
+			name:     "no sdks",
+			config:   `{"sdks": []}`,
+			wantErrs: []string{"does not list any sdks"},
+		},
+		{
+			name:   "missing fields",
+			config: `{"sdks": [{"name": "web5-js", "type": "web5"}]}`,
+			wantErrs: []string{
+				"sdks[0] (web5-js)",
+				"repo is required", "artifactName is required", "vectorPath is required",
+				"featureRegex is required", "vectorRegex is required",
+			},
+		},
+		{
+			name: "every invalid entry is reported",
+			config: `{"sdks": [` + valid + `,
+				{"name": "bad-repo", "repo": "web5-kt", "artifactName": "a", "vectorPath": "v", "type": "web5", "featureRegex": "(", "vectorRegex": "(\\w+)"},
+				{"name": "bad-options", "repo": "TBD54566975/web5-kt", "artifactName": "a", "vectorPath": "v", "type": "dwn", "featureRegex": "(\\w+)", "vectorRegex": "(\\w+)",
+					"conclusion": "passed", "maxAge": "two weeks", "format": "tap", "merge": "best", "flakyRuns": -1, "include": ["[a-"]}
+			]}`,
+			wantErrs: []string{
+				`sdks[1] (bad-repo): repo "web5-kt" must be in the form owner/name`,
+				"invalid featureRegex",
+				`sdks[2] (bad-options)`,
+				`unknown workflow run conclusion "passed"`,
+				`invalid maxAge "two weeks"`,
+				"flakyRuns must not be negative",
+				`invalid glob "[a-"`,
+				`unknown type "dwn"`,
+			},
+		},
+		{
+			name:     "duplicate name",
+			config:   `{"sdks": [` + valid + `, ` + valid + `]}`,
+			wantErrs: []string{"sdks[1] (web5-js): duplicate sdk name"},
+		},
+		{
+			name:   "regexes not needed by the mapper",
+			config: `{"sdks": [{"name": "web5-js", "repo": "TBD54566975/web5-js", "artifactName": "a", "vectorPath": "v", "type": "web5", "mapper": {"strategy": "path-segment", "separator": "::"}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sdks, err := ParseSDKs(strings.NewReader(tt.config))
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(sdks) != 1 {
+					t.Fatalf("got %d sdks, want 1", len(sdks))
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("got %d sdks, want an error", len(sdks))
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSDKsDefaults(t *testing.T) {
+	sdks, err := ParseSDKs(strings.NewReader(`{"sdks": [{"name": "web5-js", "repo": "TBD54566975/web5-js", "artifactName": "junit-results", "vectorPath": "test-vectors", "type": "web5", "featureRegex": "Web5TestVectors(\\w+)", "vectorRegex": "(\\w+)"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sdk := sdks[0]
+	if sdk.Branch != defaultBranch || sdk.MaxAge != defaultMaxAge || sdk.Format != FormatAuto || sdk.Merge != MergeFailIfAnyFailed {
+		t.Errorf("got branch %q, max age %s, format %q and merge %q, want the defaults", sdk.Branch, sdk.MaxAge, sdk.Format, sdk.Merge)
+	}
+	if _, ok := sdk.Mapper.(FeatureFromSuite); !ok {
+		t.Errorf("got mapper %T, want FeatureFromSuite", sdk.Mapper)
+	}
+
+	if _, err := LoadSDKs(""); err != nil {
+		t.Errorf("error loading the default registry: %v", err)
+	}
+}
+
+func TestParseMaxAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "", want: defaultMaxAge},
+		{in: "0", want: 0},
+		{in: "14d", want: 14 * 24 * time.Hour},
+		{in: "0d", want: 0},
+		{in: "72h", want: 72 * time.Hour},
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "-1d", wantErr: true},
+		{in: "-72h", wantErr: true},
+		{in: "1.5d", wantErr: true},
+		{in: "two weeks", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseMaxAge(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: got %s, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q: got %s, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
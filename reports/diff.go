@@ -0,0 +1,168 @@
+package reports
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// VectorChange is a vector whose status differs between two runs. Before or After is empty if the vector is absent
+// from that run.
+type VectorChange struct {
+	Feature string
+	Vector  string
+	Before  Status
+	After   Status
+}
+
+// SDKDiff lists how one SDK's results changed between two runs.
+type SDKDiff struct {
+	SDK string
+
+	// Regressed vectors passed before and now fail, error or are not implemented.
+	Regressed []VectorChange
+
+	// Fixed vectors did not pass before and now do.
+	Fixed []VectorChange
+
+	// Added and Removed vectors are only present in the later and earlier run respectively.
+	Added   []VectorChange
+	Removed []VectorChange
+
+	// Unavailable is set if the SDK had results in the earlier run but they could not be fetched for the later one.
+	// Its vectors are then not compared.
+	Unavailable bool
+
+	// Recovered is set if the SDK's results could not be fetched for the earlier run but could for the later one. Its
+	// vectors are then not compared either, as there is nothing to compare them with.
+	Recovered bool
+}
+
+func (d SDKDiff) IsEmpty() bool {
+	return !d.Unavailable && !d.Recovered && len(d.Regressed) == 0 && len(d.Fixed) == 0 && len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Diff compares two runs and returns the changes for every SDK that appears in either, sorted by SDK name. SDKs with
+// no changes are included with empty lists. An SDK whose results could not be fetched for the later run is marked
+// Unavailable rather than having all its vectors removed, and one whose results could not be fetched for the earlier
+// run is marked Recovered rather than having all its vectors added.
+func Diff(before, after HistoryEntry) []SDKDiff {
+	names := make(map[string]bool)
+	for name := range before.SDKs {
+		names[name] = true
+	}
+	for name := range after.SDKs {
+		names[name] = true
+	}
+
+	diffs := make([]SDKDiff, 0, len(names))
+	for name := range names {
+		if _, ok := before.SDKs[name]; ok && after.IsUnavailable(name) {
+			diffs = append(diffs, SDKDiff{SDK: name, Unavailable: true})
+			continue
+		}
+		if _, ok := after.SDKs[name]; ok && before.IsUnavailable(name) {
+			diffs = append(diffs, SDKDiff{SDK: name, Recovered: true})
+			continue
+		}
+		diffs = append(diffs, diffSDK(name, before.SDKs[name], after.SDKs[name]))
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].SDK < diffs[j].SDK
+	})
+
+	return diffs
+}
+
+func diffSDK(name string, before, after HistorySDK) SDKDiff {
+	diff := SDKDiff{SDK: name}
+
+	for feature, vectors := range before.Results {
+		for vector, was := range vectors {
+			now, ok := after.Results[feature][vector]
+			change := VectorChange{Feature: feature, Vector: vector, Before: was, After: now}
+			switch {
+			case !ok:
+				diff.Removed = append(diff.Removed, change)
+			case was == StatusPassed && now != StatusPassed && now != StatusSkipped:
+				diff.Regressed = append(diff.Regressed, change)
+			case was != StatusPassed && now == StatusPassed:
+				diff.Fixed = append(diff.Fixed, change)
+			}
+		}
+	}
+
+	for feature, vectors := range after.Results {
+		for vector, now := range vectors {
+			if _, ok := before.Results[feature][vector]; !ok {
+				diff.Added = append(diff.Added, VectorChange{Feature: feature, Vector: vector, After: now})
+			}
+		}
+	}
+
+	for _, changes := range [][]VectorChange{diff.Regressed, diff.Fixed, diff.Added, diff.Removed} {
+		sortVectorChanges(changes)
+	}
+
+	return diff
+}
+
+func sortVectorChanges(changes []VectorChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Feature != changes[j].Feature {
+			return changes[i].Feature < changes[j].Feature
+		}
+		return changes[i].Vector < changes[j].Vector
+	})
+}
+
+// ReadJSONReport reads a report.json written by WriteJSON.
+func ReadJSONReport(path string) (JSONReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return JSONReport{}, fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return JSONReport{}, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	if report.SchemaVersion != JSONSchemaVersion {
+		return JSONReport{}, fmt.Errorf("%s has schema version %d, expected %d", path, report.SchemaVersion, JSONSchemaVersion)
+	}
+
+	return report, nil
+}
+
+// HistoryEntry reduces a JSON report to the statuses recorded in history, so it can be compared with Diff. As with
+// NewHistoryEntry, SDKs whose results could not be fetched are only listed in Unavailable.
+func (r JSONReport) HistoryEntry() HistoryEntry {
+	entry := HistoryEntry{
+		Time: r.GeneratedAt,
+		SDKs: make(map[string]HistorySDK),
+	}
+
+	for _, sdk := range r.SDKs {
+		if sdk.FetchError != nil {
+			entry.Unavailable = append(entry.Unavailable, sdk.Name)
+			continue
+		}
+
+		h := HistorySDK{
+			Commit:  sdk.Provenance.Commit,
+			Results: make(map[string]map[string]Status),
+		}
+		for feature, vectors := range sdk.Features {
+			h.Results[feature] = make(map[string]Status)
+			for vector, result := range vectors {
+				h.Results[feature][vector] = result.Status
+			}
+		}
+		entry.SDKs[sdk.Name] = h
+	}
+
+	return entry
+}
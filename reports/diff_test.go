@@ -0,0 +1,106 @@
+package reports
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiffUnavailable(t *testing.T) {
+	passing := HistorySDK{Results: map[string]map[string]Status{"DidJwk": {"resolve": StatusPassed}}}
+
+	tests := []struct {
+		name            string
+		before, after   HistoryEntry
+		wantUnavailable bool
+		wantRecovered   bool
+		wantRemoved     int
+		wantAdded       int
+	}{
+		{
+			name:            "results no longer fetched",
+			before:          HistoryEntry{SDKs: map[string]HistorySDK{"web5-kt": passing}},
+			after:           HistoryEntry{SDKs: map[string]HistorySDK{}, Unavailable: []string{"web5-kt"}},
+			wantUnavailable: true,
+		},
+		{
+			name:        "removed from the registry",
+			before:      HistoryEntry{SDKs: map[string]HistorySDK{"web5-kt": passing}},
+			after:       HistoryEntry{SDKs: map[string]HistorySDK{}},
+			wantRemoved: 1,
+		},
+		{
+			name:          "results fetched again",
+			before:        HistoryEntry{SDKs: map[string]HistorySDK{}, Unavailable: []string{"web5-kt"}},
+			after:         HistoryEntry{SDKs: map[string]HistorySDK{"web5-kt": passing}},
+			wantRecovered: true,
+		},
+		{
+			name:      "added to the registry",
+			before:    HistoryEntry{SDKs: map[string]HistorySDK{}},
+			after:     HistoryEntry{SDKs: map[string]HistorySDK{"web5-kt": passing}},
+			wantAdded: 1,
+		},
+		{
+			name:   "unavailable in both runs",
+			before: HistoryEntry{SDKs: map[string]HistorySDK{}, Unavailable: []string{"web5-kt"}},
+			after:  HistoryEntry{SDKs: map[string]HistorySDK{}, Unavailable: []string{"web5-kt"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got SDKDiff
+			for _, diff := range Diff(tt.before, tt.after) {
+				if diff.SDK == "web5-kt" {
+					got = diff
+				}
+			}
+
+			if got.Unavailable != tt.wantUnavailable {
+				t.Errorf("got Unavailable %v, want %v", got.Unavailable, tt.wantUnavailable)
+			}
+			if got.Recovered != tt.wantRecovered {
+				t.Errorf("got Recovered %v, want %v", got.Recovered, tt.wantRecovered)
+			}
+			if len(got.Removed) != tt.wantRemoved {
+				t.Errorf("got %d removed vectors, want %d", len(got.Removed), tt.wantRemoved)
+			}
+			if len(got.Added) != tt.wantAdded {
+				t.Errorf("got %d added vectors, want %d", len(got.Added), tt.wantAdded)
+			}
+			if (got.Unavailable || got.Recovered) && got.IsEmpty() {
+				t.Error("an unavailable or recovered SDK's diff should not be empty")
+			}
+		})
+	}
+}
+
+func TestNewHistoryEntryUnavailable(t *testing.T) {
+	reports := []Report{
+		{SDK: SDKMeta{Name: "web5-js"}, Results: map[string]map[string]Result{"DidJwk": {"resolve": {Status: StatusPassed}}}},
+		{SDK: SDKMeta{Name: "web5-kt"}, FetchError: &FetchError{Kind: FetchErrorDownload}},
+	}
+
+	entry := NewHistoryEntry(reports, time.Now())
+	if _, ok := entry.SDKs["web5-kt"]; ok {
+		t.Error("unavailable SDK should not have results in the history")
+	}
+	if !entry.IsUnavailable("web5-kt") || entry.IsUnavailable("web5-js") {
+		t.Errorf("got Unavailable %v, want [web5-kt]", entry.Unavailable)
+	}
+}
+
+func TestReadJSONReportSchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "v1.json", `{"schemaVersion": 1, "sdks": []}`)
+	writeTestFile(t, dir, "current.json", fmt.Sprintf(`{"schemaVersion": %d, "sdks": []}`, JSONSchemaVersion))
+
+	if _, err := ReadJSONReport(filepath.Join(dir, "v1.json")); err == nil {
+		t.Error("expected an error reading a version 1 report, whose statuses mean something else")
+	}
+	if _, err := ReadJSONReport(filepath.Join(dir, "current.json")); err != nil {
+		t.Errorf("error reading a current report: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package reports
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	junit "github.com/joshdk/go-junit"
+)
+
+// maxFailureOutput is how many bytes of a failure's body, stdout or stderr are shown on the report page. The JSON
+// report has all of it.
+const maxFailureOutput = 8 * 1024
+
+// Failure is the detail of a test run that failed or errored. Fields are empty when the result file doesn't have them.
+type Failure struct {
+	Type    string
+	Message string
+
+	// Body is the failure's full text, usually a stack trace.
+	Body string
+
+	Stdout string
+	Stderr string
+
+	// File and Line locate the test or the failed assertion in the SDK's source.
+	File string
+	Line int
+}
+
+// Error returns the most detailed description of the failure available.
+func (f Failure) Error() string {
+	switch {
+	case strings.TrimSpace(f.Body) != "":
+		return f.Body
+	case strings.TrimSpace(f.Message) != "":
+		return f.Message
+	case f.Type != "":
+		return f.Type
+	default:
+		return "test failed"
+	}
+}
+
+// Summary is a one line description of the failure, such as "AssertionError: expected true".
+func (f Failure) Summary() string {
+	message := f.Message
+	if message == "" && f.Body != "" {
+		message, _, _ = strings.Cut(strings.TrimSpace(f.Body), "\n")
+	}
+
+	switch {
+	case f.Type != "" && message != "" && !strings.HasPrefix(message, f.Type):
+		return f.Type + ": " + message
+	case message != "":
+		return message
+	case f.Type != "":
+		return f.Type
+	default:
+		return "test failed"
+	}
+}
+
+// HasOutput reports whether there is more to the failure than its summary.
+func (f Failure) HasOutput() bool {
+	return f.Body != "" || f.Stdout != "" || f.Stderr != ""
+}
+
+// Location is "file:line", "file" or empty.
+func (f Failure) Location() string {
+	if f.File == "" {
+		return ""
+	}
+
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+
+	return f.File
+}
+
+// newFailure returns the failure details of a junit test case, or nil if it didn't fail or error.
+func newFailure(test junit.Test) *Failure {
+	if test.Status != junit.StatusFailed && test.Status != junit.StatusError {
+		return nil
+	}
+
+	f := &Failure{
+		Message: test.Message,
+		Stdout:  test.SystemOut,
+		Stderr:  test.SystemErr,
+		File:    test.Properties["file"],
+	}
+
+	switch err := test.Error.(type) {
+	case junit.Error:
+		f.Type = err.Type
+		f.Body = err.Body
+		if err.Message != "" {
+			f.Message = err.Message
+		}
+	case nil:
+	default:
+		f.Body = err.Error()
+	}
+
+	if line, err := strconv.Atoi(test.Properties["line"]); err == nil {
+		f.Line = line
+	}
+
+	if f.File == "" {
+		f.File, f.Line = findLocation(f.Body)
+	}
+
+	return f
+}
+
+// stackLocation matches the first source location in a stack trace or test output, such as "foo_test.go:12",
+// "at Object.<anonymous> (/src/foo.test.js:12:5)" or "(Foo.kt:12)".
+var stackLocation = regexp.MustCompile(`([\w./\\-]+\.(?:go|js|ts|mjs|kt|java|swift|rs)):(\d+)`)
+
+func findLocation(text string) (string, int) {
+	matches := stackLocation.FindStringSubmatch(text)
+	if matches == nil {
+		return "", 0
+	}
+
+	line, _ := strconv.Atoi(matches[2])
+	// file:// URLs leave a leading // on an absolute path
+	return strings.TrimPrefix(matches[1], "//"), line
+}
+
+// truncateOutput shortens s to maxFailureOutput bytes for display, noting how much was cut.
+func truncateOutput(s string) string {
+	if len(s) <= maxFailureOutput {
+		return s
+	}
+
+	cut := maxFailureOutput
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s\n… %d more bytes truncated", s[:cut], len(s)-cut)
+}
@@ -0,0 +1,87 @@
+package reports
+
+import (
+	"fmt"
+)
+
+const (
+	// minFlakyRuns is the fewest runs with a pass or failure that a vector needs before it can be called flaky.
+	minFlakyRuns = 3
+
+	// flakyFlipRate is the fraction of consecutive runs in which a vector must change between passing and failing to
+	// be called flaky.
+	flakyFlipRate = 0.25
+)
+
+// Flakiness summarises a vector's results over an SDK's recent workflow runs. Runs where the vector was skipped or
+// not implemented are not counted.
+type Flakiness struct {
+	Runs   int
+	Passed int
+	Failed int
+
+	// Flips is how many times the result changed between passing and failing from one run to the next.
+	Flips int
+}
+
+// Rate is the fraction of consecutive runs in which the vector flipped between passing and failing.
+func (f Flakiness) Rate() float64 {
+	if f.Runs < 2 {
+		return 0
+	}
+
+	return float64(f.Flips) / float64(f.Runs-1)
+}
+
+// IsFlaky reports whether the vector flipped often enough to be called flaky. A vector that broke or was fixed once in
+// the window flipped only once, so isn't.
+func (f Flakiness) IsFlaky() bool {
+	return f.Runs >= minFlakyRuns && f.Flips > 1 && f.Rate() >= flakyFlipRate
+}
+
+func (f Flakiness) String() string {
+	return fmt.Sprintf("flipped %d times in %d runs (%d passed, %d failed)", f.Flips, f.Runs, f.Passed, f.Failed)
+}
+
+// newFlakiness computes the flakiness of a vector from its status in each run, oldest first.
+func newFlakiness(statuses []Status) Flakiness {
+	var f Flakiness
+	var previous Status
+	for _, status := range statuses {
+		switch {
+		case status == StatusPassed:
+			f.Passed++
+		case status.IsFailure():
+			f.Failed++
+		default:
+			continue
+		}
+
+		f.Runs++
+		if previous != "" && (previous == StatusPassed) != (status == StatusPassed) {
+			f.Flips++
+		}
+		previous = status
+	}
+
+	return f
+}
+
+// addFlakiness sets the flakiness of every vector in the report from older reports of the same SDK, newest first.
+func (r *Report) addFlakiness(older []Report) {
+	for feature, vectors := range r.Results {
+		for vector, result := range vectors {
+			statuses := make([]Status, 0, len(older)+1)
+			for i := len(older) - 1; i >= 0; i-- {
+				if previous, ok := older[i].Results[feature][vector]; ok {
+					statuses = append(statuses, previous.Status)
+				}
+			}
+			statuses = append(statuses, result.Status)
+
+			flakiness := newFlakiness(statuses)
+			result.Flakiness = &flakiness
+			r.Results[feature][vector] = result
+		}
+	}
+}
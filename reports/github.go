@@ -2,63 +2,228 @@ package reports
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 
 	ghinstallation "github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v57/github"
 	"golang.org/x/exp/slog"
 )
 
-var (
-	ghAppName                 = os.Getenv("CICD_ROBOT_GITHUB_APP_NAME")
-	ghAppPrivateKey           = os.Getenv("CICD_ROBOT_GITHUB_APP_PRIVATE_KEY")
-	ghAppIDString             = os.Getenv("CICD_ROBOT_GITHUB_APP_ID")
-	ghAppInstallationIDString = os.Getenv("CICD_ROBOT_GITHUB_APP_INSTALLATION_ID")
+// GitHubConfig describes how to connect to GitHub. Token takes precedence over the GitHub App fields. If neither is set,
+// requests are made unauthenticated.
+type GitHubConfig struct {
+	// Token is a personal access token or a workflow's GITHUB_TOKEN.
+	Token string
 
-	ghToken = os.Getenv("GITHUB_TOKEN")
+	// AppID, AppInstallationID and AppPrivateKey authenticate as an installation of a GitHub App. AppName is the app's
+	// URL name, used to attribute the commits made by sync-vectors. Syncing vectors requires an app.
+	AppName           string
+	AppID             int64
+	AppInstallationID int64
+	AppPrivateKey     []byte
 
-	gh          *github.Client
-	ghTransport *ghinstallation.Transport
+	// HTTPClient is used for all requests if set, instead of http.DefaultClient. Authentication is layered on top of its
+	// transport.
+	HTTPClient *http.Client
 
-	ghUserName = fmt.Sprintf("%s[bot]", ghAppName)
-)
+	// BaseURL is the root of the REST API if set, such as https://github.example.com/api/v3/ for GitHub Enterprise or the
+	// address of a local fake server.
+	BaseURL string
+
+	// ServerURL is the web root of the GitHub server if set, such as https://github.example.com for GitHub Enterprise.
+	// sync-vectors clones repos and stores git credentials for it. Defaults to https://github.com.
+	ServerURL string
+}
+
+const defaultServerURL = "https://github.com"
 
-func init() {
-	if ghToken != "" {
-		slog.Info("using GITHUB_TOKEN for auth")
-		gh = github.NewTokenClient(context.Background(), ghToken)
-		return
+// GitHubConfigFromEnv reads a GitHubConfig from GITHUB_TOKEN, or from the CICD_ROBOT_GITHUB_APP_* variables if
+// GITHUB_TOKEN is not set. The server is read from GITHUB_API_URL and GITHUB_SERVER_URL, as set by GitHub Actions, if
+// they are set.
+func GitHubConfigFromEnv() (GitHubConfig, error) {
+	config, err := githubCredentialsFromEnv()
+	if err != nil {
+		return GitHubConfig{}, err
 	}
 
-	ghAppID, err := strconv.ParseInt(ghAppIDString, 10, 32)
+	config.BaseURL = os.Getenv("GITHUB_API_URL")
+	config.ServerURL = os.Getenv("GITHUB_SERVER_URL")
+
+	return config, nil
+}
+
+func githubCredentialsFromEnv() (GitHubConfig, error) {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return GitHubConfig{Token: token}, nil
+	}
+
+	appID, err := strconv.ParseInt(os.Getenv("CICD_ROBOT_GITHUB_APP_ID"), 10, 64)
 	if err != nil {
-		slog.Error("invalid or unset app ID. Please set environment variable CICD_ROBOT_GITHUB_APP_ID to a valid integer")
-		panic(err)
+		return GitHubConfig{}, errors.New("GITHUB_TOKEN is not set and CICD_ROBOT_GITHUB_APP_ID is not a valid integer")
 	}
 
-	ghInstallationID, err := strconv.ParseInt(ghAppInstallationIDString, 10, 32)
+	installationID, err := strconv.ParseInt(os.Getenv("CICD_ROBOT_GITHUB_APP_INSTALLATION_ID"), 10, 64)
 	if err != nil {
-		slog.Error("invalid or unset installation ID. Please set environment variable CICD_ROBOT_GITHUB_APP_INSTALLATION_ID to a valid integer")
-		panic(err)
+		return GitHubConfig{}, errors.New("GITHUB_TOKEN is not set and CICD_ROBOT_GITHUB_APP_INSTALLATION_ID is not a valid integer")
+	}
+
+	return GitHubConfig{
+		AppName:           os.Getenv("CICD_ROBOT_GITHUB_APP_NAME"),
+		AppID:             appID,
+		AppInstallationID: installationID,
+		AppPrivateKey:     []byte(os.Getenv("CICD_ROBOT_GITHUB_APP_PRIVATE_KEY")),
+	}, nil
+}
+
+// GitHub is a GitHub API client along with the credentials needed to download artifacts and push vector updates.
+type GitHub struct {
+	client *github.Client
+
+	// httpClient is used to fetch artifact archives. GitHub redirects artifact downloads to blob storage, which must
+	// not be sent our credentials, so this client does not add any.
+	httpClient *http.Client
+
+	appTransport *ghinstallation.Transport
+	appName      string
+
+	// serverURL is the web root of the server, see GitHubConfig.ServerURL.
+	serverURL *url.URL
+
+	// runs caches workflow runs, as SDKs that share a repo, such as web5-rs and web5-core-kt, look up the same ones.
+	runsMu sync.Mutex
+	runs   map[workflowRunKey]*github.WorkflowRun
+}
+
+// cloneURL is the https URL to clone repo, as owner/name, from.
+func (g *GitHub) cloneURL(repo string) string {
+	return g.serverURL.JoinPath(repo).String()
+}
+
+type workflowRunKey struct {
+	repo string
+	id   int64
+}
+
+// getWorkflowRun gets a workflow run of owner/repo, from the cache if it was already fetched.
+func (g *GitHub) getWorkflowRun(ctx context.Context, owner, repo string, id int64) (*github.WorkflowRun, error) {
+	key := workflowRunKey{repo: owner + "/" + repo, id: id}
+
+	g.runsMu.Lock()
+	run, ok := g.runs[key]
+	g.runsMu.Unlock()
+	if ok {
+		return run, nil
 	}
 
-	ghTransport, err = ghinstallation.New(http.DefaultTransport, ghAppID, ghInstallationID, []byte(ghAppPrivateKey))
+	run, _, err := g.client.Actions.GetWorkflowRunByID(ctx, owner, repo, id)
 	if err != nil {
-		slog.Error("error initializing github auth transport.")
-		panic(err)
+		return nil, err
 	}
 
-	gh = github.NewClient(&http.Client{Transport: ghTransport})
+	g.runsMu.Lock()
+	if g.runs == nil {
+		g.runs = make(map[workflowRunKey]*github.WorkflowRun)
+	}
+	g.runs[key] = run
+	g.runsMu.Unlock()
 
-	user, _, err := gh.Users.Get(context.Background(), ghUserName)
+	return run, nil
+}
+
+// NewGitHub creates a GitHub client. It does not make any requests.
+func NewGitHub(config GitHubConfig) (*GitHub, error) {
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	serverURL, err := url.Parse(firstNonEmpty(config.ServerURL, defaultServerURL))
+	if err != nil || serverURL.Host == "" {
+		return nil, fmt.Errorf("invalid github server url %q", config.ServerURL)
+	}
+	serverURL.Path = strings.TrimSuffix(serverURL.Path, "/")
+
+	g := &GitHub{
+		httpClient: httpClient,
+		appName:    config.AppName,
+		serverURL:  serverURL,
+	}
+
+	switch {
+	case config.Token != "":
+		slog.Info("using token for github auth")
+		// WithAuthToken wraps the transport of the client it is given in place, so it gets a copy to keep the token off
+		// httpClient
+		apiClient := *httpClient
+		g.client = github.NewClient(&apiClient).WithAuthToken(config.Token)
+	case config.AppID != 0:
+		slog.Info("using github app for github auth", "app", config.AppName)
+		transport := httpClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+
+		appTransport, err := ghinstallation.New(transport, config.AppID, config.AppInstallationID, config.AppPrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing github app auth transport: %v", err)
+		}
+		if config.BaseURL != "" {
+			appTransport.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+		}
+
+		g.appTransport = appTransport
+		g.client = github.NewClient(&http.Client{Transport: appTransport, Timeout: httpClient.Timeout})
+	default:
+		slog.Warn("no github credentials configured, making unauthenticated requests")
+		g.client = github.NewClient(httpClient)
+	}
+
+	if config.BaseURL != "" {
+		baseURL, err := url.Parse(config.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid github base url: %v", err)
+		}
+		if !strings.HasSuffix(baseURL.Path, "/") {
+			baseURL.Path += "/"
+		}
+		g.client.BaseURL = baseURL
+	}
+
+	return g, nil
+}
+
+// Client returns the underlying go-github client.
+func (g *GitHub) Client() *github.Client {
+	return g.client
+}
+
+// gitCredentials returns the username and password git should use to push to repos the app is installed on, and the
+// identity commits should be made with.
+func (g *GitHub) gitCredentials(ctx context.Context) (username string, password string, email string, err error) {
+	if g.appTransport == nil {
+		return "", "", "", errors.New("syncing vectors with a PAT not supported. See reports/README.md for instructions to create a GitHub app")
+	}
+
+	username = fmt.Sprintf("%s[bot]", g.appName)
+
+	user, _, err := g.client.Users.Get(ctx, username)
+	if err != nil {
+		return "", "", "", fmt.Errorf("error getting own (app) user info: %v", err)
+	}
+
+	password, err = g.appTransport.Token(ctx)
 	if err != nil {
-		slog.Error("error getting own (app) user info")
-		panic(err)
+		return "", "", "", fmt.Errorf("error getting github auth token: %v", err)
 	}
 
-	gitConfig["user.email"] = fmt.Sprintf("%d+%s@users.noreply.github.com", user.GetID(), ghUserName)
-	gitConfig["user.name"] = ghUserName
+	email = fmt.Sprintf("%d+%s@users.noreply.github.com", user.GetID(), username)
+
+	return username, password, email, nil
 }
@@ -0,0 +1,33 @@
+package reports
+
+import "testing"
+
+func TestGitHubServerURL(t *testing.T) {
+	tests := []struct {
+		serverURL string
+		wantClone string
+		wantHost  string
+	}{
+		{"", "https://github.com/TBD54566975/web5-js", "github.com"},
+		{"https://github.example.com/", "https://github.example.com/TBD54566975/web5-js", "github.example.com"},
+		{"https://example.com/github", "https://example.com/github/TBD54566975/web5-js", "example.com"},
+	}
+
+	for _, tt := range tests {
+		gh, err := NewGitHub(GitHubConfig{ServerURL: tt.serverURL})
+		if err != nil {
+			t.Fatalf("%q: %v", tt.serverURL, err)
+		}
+
+		if got := gh.cloneURL("TBD54566975/web5-js"); got != tt.wantClone {
+			t.Errorf("%q: got clone url %s, want %s", tt.serverURL, got, tt.wantClone)
+		}
+		if got := gh.serverURL.Host; got != tt.wantHost {
+			t.Errorf("%q: got credential host %s, want %s", tt.serverURL, got, tt.wantHost)
+		}
+	}
+
+	if _, err := NewGitHub(GitHubConfig{ServerURL: "github.example.com"}); err == nil {
+		t.Error("expected an error for a server url without a scheme")
+	}
+}
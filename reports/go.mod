@@ -7,6 +7,7 @@ require (
 	github.com/essentialkaos/go-badge v1.3.3
 	github.com/google/go-github/v57 v57.0.0
 	github.com/joshdk/go-junit v1.0.0
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	golang.org/x/exp v0.0.0-20231127185646-65229373498e
 )
 
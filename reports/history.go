@@ -0,0 +1,270 @@
+package reports
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// maxTrendRuns is how many of the most recent runs are plotted in the per-SDK trend.
+const maxTrendRuns = 30
+
+// HistoryEntry is the record of a single report run, stored one per line in the history file.
+type HistoryEntry struct {
+	Time time.Time             `json:"time"`
+	SDKs map[string]HistorySDK `json:"sdks"`
+
+	// Unavailable lists the SDKs whose results could not be fetched, which are left out of SDKs.
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// IsUnavailable reports whether the named SDK's results could not be fetched in this run.
+func (e HistoryEntry) IsUnavailable(sdk string) bool {
+	return slices.Contains(e.Unavailable, sdk)
+}
+
+// HistorySDK is the outcome of every vector for one SDK in a run, and the SDK commit it was tested at if known.
+type HistorySDK struct {
+	Commit string `json:"commit,omitempty"`
+
+	// Results maps feature name to vector name to status.
+	Results map[string]map[string]Status `json:"results"`
+}
+
+func (h HistorySDK) Passing() int {
+	passing := 0
+	for _, vectors := range h.Results {
+		for _, status := range vectors {
+			if status == StatusPassed {
+				passing++
+			}
+		}
+	}
+
+	return passing
+}
+
+func (h HistorySDK) Total() int {
+	total := 0
+	for _, vectors := range h.Results {
+		total += len(vectors)
+	}
+
+	return total
+}
+
+// NewHistoryEntry records the outcome of reports as a HistoryEntry. SDKs whose results could not be fetched are only
+// listed in Unavailable, so they don't show up as a drop in the trend, but Diff can still tell them apart from SDKs
+// that were removed.
+func NewHistoryEntry(reports []Report, t time.Time) HistoryEntry {
+	entry := HistoryEntry{
+		Time: t.UTC(),
+		SDKs: make(map[string]HistorySDK),
+	}
+
+	for _, report := range reports {
+		if report.FetchError != nil {
+			entry.Unavailable = append(entry.Unavailable, report.SDK.Name)
+			continue
+		}
+
+		sdk := HistorySDK{
+			Commit:  report.Provenance.CommitSHA,
+			Results: make(map[string]map[string]Status),
+		}
+		for feature, vectors := range report.Results {
+			sdk.Results[feature] = make(map[string]Status)
+			for vector, result := range vectors {
+				sdk.Results[feature][vector] = result.Status
+			}
+		}
+		entry.SDKs[report.SDK.Name] = sdk
+	}
+
+	return entry
+}
+
+// ReadHistory reads every entry from the JSON lines history file at path, oldest first. A missing file is treated as
+// an empty history.
+func ReadHistory(path string) ([]HistoryEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Info("no history file found, starting a new one", "file", path)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening history file: %v", err)
+	}
+	defer f.Close()
+
+	var history []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("error parsing %s line %d: %v", path, line, err)
+		}
+		history = append(history, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history file: %v", err)
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Time.Before(history[j].Time)
+	})
+
+	return history, nil
+}
+
+// AppendHistory adds entry to the end of the history file at path, creating it if needed. Existing entries are never
+// rewritten.
+func AppendHistory(path string, entry HistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding history entry: %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening history file: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("error writing history file: %v", err)
+	}
+
+	slog.Info("recorded run in history", "file", path, "time", entry.Time)
+
+	return nil
+}
+
+// Trend is the number of passing vectors for an SDK over recent runs, oldest first.
+type Trend struct {
+	SDK    string
+	Points []TrendPoint
+}
+
+type TrendPoint struct {
+	Time    time.Time
+	Passing int
+	Total   int
+}
+
+func (t Trend) Latest() TrendPoint {
+	if len(t.Points) == 0 {
+		return TrendPoint{}
+	}
+
+	return t.Points[len(t.Points)-1]
+}
+
+// Change is the difference in passing vectors between the last two runs.
+func (t Trend) Change() int {
+	if len(t.Points) < 2 {
+		return 0
+	}
+
+	return t.Points[len(t.Points)-1].Passing - t.Points[len(t.Points)-2].Passing
+}
+
+// SparklinePoints renders the trend as the points attribute of an SVG polyline in a width x height box.
+func (t Trend) SparklinePoints(width, height int) string {
+	if len(t.Points) == 0 {
+		return ""
+	}
+
+	max := 0
+	for _, p := range t.Points {
+		if p.Total > max {
+			max = p.Total
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+
+	step := 0.0
+	if len(t.Points) > 1 {
+		step = float64(width) / float64(len(t.Points)-1)
+	}
+
+	points := make([]string, len(t.Points))
+	for i, p := range t.Points {
+		x := step * float64(i)
+		y := float64(height) - float64(p.Passing)/float64(max)*float64(height)
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+
+	return strings.Join(points, " ")
+}
+
+// Trends returns the pass count trend over the most recent runs in history for each of the named SDKs.
+func Trends(history []HistoryEntry, sdks []string) []Trend {
+	if len(history) > maxTrendRuns {
+		history = history[len(history)-maxTrendRuns:]
+	}
+
+	trends := make([]Trend, 0, len(sdks))
+	for _, name := range sdks {
+		trend := Trend{SDK: name}
+		for _, entry := range history {
+			sdk, ok := entry.SDKs[name]
+			if !ok {
+				continue
+			}
+			trend.Points = append(trend.Points, TrendPoint{
+				Time:    entry.Time,
+				Passing: sdk.Passing(),
+				Total:   sdk.Total(),
+			})
+		}
+		trends = append(trends, trend)
+	}
+
+	return trends
+}
+
+// Regression is a vector that passed in one run and did not in a later one.
+type Regression struct {
+	SDK     string
+	Feature string
+	Vector  string
+	Before  Status
+	After   Status
+}
+
+// FindRegressions lists the vectors that passed in previous but fail, error or are not implemented in current, see
+// Diff.
+func FindRegressions(previous, current HistoryEntry) []Regression {
+	var regressions []Regression
+	for _, diff := range Diff(previous, current) {
+		for _, change := range diff.Regressed {
+			regressions = append(regressions, Regression{
+				SDK:     diff.SDK,
+				Feature: change.Feature,
+				Vector:  change.Vector,
+				Before:  change.Before,
+				After:   change.After,
+			})
+		}
+	}
+
+	return regressions
+}
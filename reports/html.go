@@ -20,45 +20,67 @@ func sanatizeHTML(dirty error) string {
 
 type htmlTemplateInput struct {
 	Reports      []Report
-	Web5Reports  []Report
-	TbdexReports []Report
-	Web5Tests    map[string][]string
-	TbDEXTests   map[string][]string
+	Suites       []htmlSuite
+	HasUnmatched bool
+	Trends       []Trend
+	Regressions  []Regression
+	Releases     *htmlReleaseMatrix
 	CreationTime string
 }
 
-func WriteHTML(reports []Report, destinationDir string) error {
+// htmlReleaseMatrix is the compliance matrix from ComplianceMatrix, with a row per SDK and a column per release.
+type htmlReleaseMatrix struct {
+	Releases []string
+	Rows     []htmlReleaseRow
+}
+
+type htmlReleaseRow struct {
+	SDK string
+
+	// Cells has one entry per release, nil where the SDK isn't tested against that release's suite.
+	Cells []*ReleaseCompliance
+}
+
+// htmlSuite is a vector suite and the reports of the SDKs tested against it.
+type htmlSuite struct {
+	VectorSuite
+	Reports []Report
+
+	// Tests maps feature to the names of its vectors.
+	Tests   map[string][]string
+	Vectors map[string]map[string]Vector
+}
+
+// WriteHTML writes index.html and the badges for reports to destinationDir. history is optional; if given, its last
+// entry should be the run the reports came from, and the page will include per-SDK trends and regressions since the
+// run before. releases is also optional, the compliance matrix from ComplianceMatrix.
+func WriteHTML(reports []Report, history []HistoryEntry, releases []ReleaseCompliance, destinationDir string) error {
 	slog.Info("writing html report", "reports", len(reports))
 
-	testmap := make(map[string]map[string]bool)
-	tbdexTestMap := make(map[string]map[string]bool)
+	// suite name -> feature -> vector
+	testMaps := make(map[string]map[string]map[string]bool)
 	for _, report := range reports {
-		badge := Badge{Name: report.SDK.Name}
+		badge := Badge{Name: report.SDK.Name, Stale: report.Stale, Unavailable: report.FetchError != nil}
 		slog.Info("debug", "result_count", len(report.Results))
+		if testMaps[report.SDK.Type] == nil {
+			testMaps[report.SDK.Type] = make(map[string]map[string]bool)
+		}
+		testmap := testMaps[report.SDK.Type]
 		for category, tests := range report.Results {
-			if _, ok := tests[category]; !ok {
-				if report.SDK.Type == "web5" {
-					testmap[category] = map[string]bool{}
-				} else {
-					tbdexTestMap[category] = map[string]bool{}
-				}
+			if _, ok := testmap[category]; !ok {
+				testmap[category] = map[string]bool{}
 			}
 
 			badge.Total += len(tests)
 
 			for test := range tests {
-				if report.SDK.Type == "web5" {
-					testmap[category][test] = true
-				} else {
-					tbdexTestMap[category][test] = true
-				}
+				testmap[category][test] = true
 
-				if tests[test].Exists {
-					if len(tests[test].Errors) > 0 {
-						badge.Error = true
-					} else {
-						badge.Passing += 1
-					}
+				switch status := tests[test].Status; {
+				case status.IsFailure():
+					badge.Error = true
+				case status == StatusPassed:
+					badge.Passing += 1
 				}
 			}
 		}
@@ -68,39 +90,58 @@ func WriteHTML(reports []Report, destinationDir string) error {
 		}
 	}
 
-	var web5Reports []Report
-	for _, report := range reports {
-		if report.SDK.Type == "web5" {
-			web5Reports = append(web5Reports, report)
+	templateInput := htmlTemplateInput{
+		Reports:      reports,
+		CreationTime: time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	for _, vectorSuite := range VectorSuites {
+		vectors, err := KnownVectors(vectorSuite.Name)
+		if err != nil {
+			return fmt.Errorf("error reading test vectors: %v", err)
 		}
+
+		suite := htmlSuite{
+			VectorSuite: vectorSuite,
+			Tests:       make(map[string][]string),
+			Vectors:     vectors,
+		}
+
+		for _, report := range reports {
+			if report.SDK.Type == vectorSuite.Name {
+				suite.Reports = append(suite.Reports, report)
+			}
+		}
+
+		for category, tests := range testMaps[vectorSuite.Name] {
+			for test := range tests {
+				suite.Tests[category] = append(suite.Tests[category], test)
+			}
+		}
+
+		templateInput.Suites = append(templateInput.Suites, suite)
 	}
 
-	var tbdexReports []Report
 	for _, report := range reports {
-		if report.SDK.Type == "tbdex" {
-			tbdexReports = append(tbdexReports, report)
+		if len(report.Unmatched) > 0 {
+			templateInput.HasUnmatched = true
 		}
 	}
 
-	templateInput := htmlTemplateInput{
-		Reports:      reports,
-		Web5Reports:  web5Reports,
-		TbdexReports: tbdexReports,
-		Web5Tests:    make(map[string][]string),
-		TbDEXTests:   make(map[string][]string),
-		CreationTime: time.Now().Format("2006-01-02 15:04:05"),
+	if len(history) > 0 {
+		names := make([]string, len(reports))
+		for i, report := range reports {
+			names[i] = report.SDK.Name
+		}
+		templateInput.Trends = Trends(history, names)
 	}
 
-	for category, tests := range testmap {
-		for test := range tests {
-			templateInput.Web5Tests[category] = append(templateInput.Web5Tests[category], test)
-		}
+	if len(history) > 1 {
+		templateInput.Regressions = FindRegressions(history[len(history)-2], history[len(history)-1])
 	}
 
-	for category, tests := range tbdexTestMap {
-		for test := range tests {
-			templateInput.TbDEXTests[category] = append(templateInput.TbDEXTests[category], test)
-		}
+	if len(releases) > 0 {
+		templateInput.Releases = newHTMLReleaseMatrix(releases)
 	}
 
 	indexFilename := filepath.Join(destinationDir, "index.html")
@@ -117,3 +158,38 @@ func WriteHTML(reports []Report, destinationDir string) error {
 
 	return nil
 }
+
+func newHTMLReleaseMatrix(releases []ReleaseCompliance) *htmlReleaseMatrix {
+	matrix := &htmlReleaseMatrix{}
+
+	// releases and SDKs are kept in the order they first appear in
+	columns := make(map[string]int)
+	rows := make(map[string]int)
+	for i, release := range releases {
+		label := release.Suite + " " + release.Version
+		if suite, ok := FindVectorSuite(release.Suite); ok {
+			label = suite.Title + " " + release.Version
+		}
+
+		column, ok := columns[label]
+		if !ok {
+			column = len(matrix.Releases)
+			columns[label] = column
+			matrix.Releases = append(matrix.Releases, label)
+			for r := range matrix.Rows {
+				matrix.Rows[r].Cells = append(matrix.Rows[r].Cells, nil)
+			}
+		}
+
+		row, ok := rows[release.SDK]
+		if !ok {
+			row = len(matrix.Rows)
+			rows[release.SDK] = row
+			matrix.Rows = append(matrix.Rows, htmlReleaseRow{SDK: release.SDK, Cells: make([]*ReleaseCompliance, len(matrix.Releases))})
+		}
+
+		matrix.Rows[row].Cells[column] = &releases[i]
+	}
+
+	return matrix
+}
@@ -0,0 +1,298 @@
+package reports
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// JSONSchemaVersion is the version of the report.json format. It is bumped whenever a field is removed or changes
+// meaning; adding fields does not change it. Version 2 added the unavailable and not-implemented statuses and split
+// errored out of failed.
+const JSONSchemaVersion = 2
+
+const jsonReportFilename = "report.json"
+
+// JSONReport is the top level of report.json.
+type JSONReport struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	GeneratedAt   time.Time `json:"generatedAt"`
+	SDKs          []JSONSDK `json:"sdks"`
+
+	// Releases is the compliance of each SDK with the spec releases the report was checked against, if any.
+	Releases []JSONReleaseCompliance `json:"releases,omitempty"`
+}
+
+// JSONSDK is the report for a single SDK.
+type JSONSDK struct {
+	Name         string          `json:"name"`
+	Repo         string          `json:"repo"`
+	Type         string          `json:"type"`
+	ArtifactName string          `json:"artifactName"`
+	Branch       string          `json:"branch"`
+	Provenance   JSONProvenance  `json:"provenance"`
+	Stale        bool            `json:"stale"`
+	FetchError   *JSONFetchError `json:"fetchError"`
+	Submodule    JSONSubmodule   `json:"submodule"`
+	Passing      bool            `json:"passing"`
+
+	// Features maps feature name to vector name to result.
+	Features  map[string]map[string]JSONResult `json:"features"`
+	Unmatched []JSONUnmatchedTest              `json:"unmatched"`
+}
+
+// JSONProvenance identifies the SDK commit, workflow run and artifact the results were read from. Fields that aren't
+// known are empty, zero or null.
+type JSONProvenance struct {
+	Commit            string     `json:"commit"`
+	RunID             int64      `json:"runId"`
+	RunURL            string     `json:"runUrl"`
+	ArtifactCreatedAt *time.Time `json:"artifactCreatedAt"`
+	ArtifactSize      int64      `json:"artifactSize"`
+}
+
+// JSONFetchError is why an SDK's results could not be fetched. Kind is one of "no-artifacts", "no-matching-artifact",
+// "download" or "parse".
+type JSONFetchError struct {
+	Kind    FetchErrorKind `json:"kind"`
+	Message string         `json:"message"`
+}
+
+// JSONSubmodule describes the vector submodule in the SDK's repo. CommitsBehind is null if it could not be determined.
+type JSONSubmodule struct {
+	Commit        string `json:"commit"`
+	CommitsBehind *int   `json:"commitsBehind"`
+}
+
+// JSONResult is the outcome of a single test vector. Status is one of "passed", "failed", "errored", "skipped",
+// "not-implemented", "unknown" or "unavailable".
+type JSONResult struct {
+	Status     Status    `json:"status"`
+	DurationMS int64     `json:"durationMs"`
+	Errors     []string  `json:"errors"`
+	Runs       []JSONRun `json:"runs"`
+
+	// Failures has the details behind each of Errors.
+	Failures []JSONFailure `json:"failures"`
+
+	// Platforms maps platform name to the vector's status on that platform. It is empty if no platform is known.
+	Platforms map[string]Status `json:"platforms"`
+
+	// Flakiness is null unless flaky test detection is on for the SDK.
+	Flakiness *JSONFlakiness `json:"flakiness"`
+}
+
+// JSONFlakiness is a vector's results over the SDK's recent workflow runs, see Flakiness.
+type JSONFlakiness struct {
+	Runs   int     `json:"runs"`
+	Passed int     `json:"passed"`
+	Failed int     `json:"failed"`
+	Flips  int     `json:"flips"`
+	Rate   float64 `json:"rate"`
+	Flaky  bool    `json:"flaky"`
+}
+
+// JSONRun is one of the test runs a JSONResult was merged from.
+type JSONRun struct {
+	File       string   `json:"file"`
+	Platform   string   `json:"platform"`
+	Status     Status   `json:"status"`
+	DurationMS int64    `json:"durationMs"`
+	Errors     []string `json:"errors"`
+
+	// Failure is null unless the run failed or errored.
+	Failure *JSONFailure `json:"failure"`
+}
+
+// JSONFailure is the detail of a failed or errored test run, see Failure. Fields the result file didn't have are empty
+// or 0.
+type JSONFailure struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Body    string `json:"body"`
+	Stdout  string `json:"stdout"`
+	Stderr  string `json:"stderr"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// JSONUnmatchedTest is a test case that did not match a known vector, see UnmatchedTest.
+type JSONUnmatchedTest struct {
+	Suite   string `json:"suite"`
+	Test    string `json:"test"`
+	Feature string `json:"feature"`
+	Vector  string `json:"vector"`
+	Reason  string `json:"reason"`
+}
+
+// JSONReleaseCompliance is an SDK's compliance with one spec release, see ReleaseCompliance.
+type JSONReleaseCompliance struct {
+	SDK         string `json:"sdk"`
+	Suite       string `json:"suite"`
+	Version     string `json:"version"`
+	Passing     int    `json:"passing"`
+	Total       int    `json:"total"`
+	Compliant   bool   `json:"compliant"`
+	Unavailable bool   `json:"unavailable"`
+}
+
+// WriteJSON writes the reports, and the compliance matrix from ComplianceMatrix if any, to report.json in
+// destinationDir.
+func WriteJSON(reports []Report, releases []ReleaseCompliance, destinationDir string) error {
+	filename := filepath.Join(destinationDir, jsonReportFilename)
+	slog.Info("writing json report", "file", filename, "reports", len(reports))
+
+	report := NewJSONReport(reports, time.Now())
+	for _, release := range releases {
+		report.Releases = append(report.Releases, JSONReleaseCompliance{
+			SDK:         release.SDK,
+			Suite:       release.Suite,
+			Version:     release.Version,
+			Passing:     release.Passing,
+			Total:       release.Total,
+			Compliant:   release.IsCompliant(),
+			Unavailable: release.Unavailable,
+		})
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding json report: %v", err)
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", filename, err)
+	}
+
+	return nil
+}
+
+// NewJSONReport converts reports to their report.json representation.
+func NewJSONReport(reports []Report, generatedAt time.Time) JSONReport {
+	out := JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		GeneratedAt:   generatedAt.UTC(),
+		SDKs:          make([]JSONSDK, 0, len(reports)),
+	}
+
+	for _, report := range reports {
+		sdk := JSONSDK{
+			Name:         report.SDK.Name,
+			Repo:         report.SDK.Repo,
+			Type:         report.SDK.Type,
+			ArtifactName: report.SDK.ArtifactName,
+			Branch:       report.SDK.Branch,
+			Stale:        report.Stale,
+			Provenance: JSONProvenance{
+				Commit:       report.Provenance.CommitSHA,
+				RunID:        report.Provenance.RunID,
+				RunURL:       report.Provenance.RunURL,
+				ArtifactSize: report.Provenance.Size,
+			},
+			Submodule: JSONSubmodule{Commit: report.SDK.SubmoduleCommit},
+			Passing:   report.IsPassing(),
+			Features:  make(map[string]map[string]JSONResult),
+			Unmatched: make([]JSONUnmatchedTest, 0, len(report.Unmatched)),
+		}
+
+		if report.FetchError != nil {
+			sdk.FetchError = &JSONFetchError{Kind: report.FetchError.Kind, Message: report.FetchError.Error()}
+		}
+
+		if !report.Provenance.CreatedAt.IsZero() {
+			createdAt := report.Provenance.CreatedAt.UTC()
+			sdk.Provenance.ArtifactCreatedAt = &createdAt
+		}
+
+		if report.SDK.SubmoduleCommitBehind >= 0 {
+			behind := report.SDK.SubmoduleCommitBehind
+			sdk.Submodule.CommitsBehind = &behind
+		}
+
+		for feature, vectors := range report.Results {
+			sdk.Features[feature] = make(map[string]JSONResult)
+			for vector, result := range vectors {
+				sdk.Features[feature][vector] = newJSONResult(result)
+			}
+		}
+
+		for _, u := range report.Unmatched {
+			sdk.Unmatched = append(sdk.Unmatched, JSONUnmatchedTest{
+				Suite:   u.Suite,
+				Test:    u.Test,
+				Feature: u.Feature,
+				Vector:  u.Vector,
+				Reason:  u.Reason(),
+			})
+		}
+
+		out.SDKs = append(out.SDKs, sdk)
+	}
+
+	return out
+}
+
+func newJSONResult(result Result) JSONResult {
+	r := JSONResult{
+		Status:     result.Status,
+		DurationMS: result.Time.Milliseconds(),
+		Errors:     make([]string, 0, len(result.Failures)),
+		Failures:   make([]JSONFailure, 0, len(result.Failures)),
+		Runs:       make([]JSONRun, 0, len(result.Runs)),
+		Platforms:  make(map[string]Status, len(result.Platforms)),
+	}
+
+	for _, failure := range result.Failures {
+		r.Errors = append(r.Errors, failure.Error())
+		r.Failures = append(r.Failures, newJSONFailure(failure))
+	}
+
+	for _, run := range result.Runs {
+		jsonRun := JSONRun{
+			File:       run.File,
+			Platform:   run.Platform,
+			Status:     run.Status,
+			DurationMS: run.Time.Milliseconds(),
+			Errors:     []string{},
+		}
+		if run.Failure != nil {
+			jsonRun.Errors = append(jsonRun.Errors, run.Failure.Error())
+			failure := newJSONFailure(*run.Failure)
+			jsonRun.Failure = &failure
+		}
+		r.Runs = append(r.Runs, jsonRun)
+	}
+
+	for _, platform := range result.Platforms {
+		r.Platforms[platform.Platform] = platform.Status
+	}
+
+	if f := result.Flakiness; f != nil {
+		r.Flakiness = &JSONFlakiness{
+			Runs:   f.Runs,
+			Passed: f.Passed,
+			Failed: f.Failed,
+			Flips:  f.Flips,
+			Rate:   f.Rate(),
+			Flaky:  f.IsFlaky(),
+		}
+	}
+
+	return r
+}
+
+func newJSONFailure(f Failure) JSONFailure {
+	return JSONFailure{
+		Type:    f.Type,
+		Message: f.Message,
+		Body:    f.Body,
+		Stdout:  f.Stdout,
+		Stderr:  f.Stderr,
+		File:    f.File,
+		Line:    f.Line,
+	}
+}
@@ -1,33 +1,217 @@
 package reports
 
 import (
+	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 
-	junit "github.com/joshdk/go-junit"
 	"golang.org/x/exp/slog"
 )
 
-func readArtifactZip(artifact []byte) ([]junit.Suite, error) {
-	z, err := zip.NewReader(bytes.NewReader(artifact), int64(len(artifact)))
+const (
+	// maxArchiveDepth is how many levels of archives inside the artifact are opened. Deeper archives are skipped.
+	maxArchiveDepth = 3
+
+	// maxArtifactSize limits the total uncompressed size of everything read from one artifact, including the contents
+	// of nested archives.
+	maxArtifactSize int64 = 1 << 30
+)
+
+// artifactReader reads the test result files for an SDK out of an artifact, opening zip, tar and tar.gz archives
+// found inside it.
+type artifactReader struct {
+	sdk       SDKMeta
+	remaining int64
+}
+
+func newArtifactReader(sdk SDKMeta) *artifactReader {
+	return &artifactReader{sdk: sdk, remaining: maxArtifactSize}
+}
+
+func readArtifactZip(artifact []byte, sdk SDKMeta) ([]Suite, error) {
+	return newArtifactReader(sdk).readZip("", artifact, 0)
+}
+
+// readResultsDir reads every test result file under dir, as readArtifactZip does for an archive.
+func readResultsDir(dir string, sdk SDKMeta) ([]Suite, error) {
+	r := newArtifactReader(sdk)
+
+	suites := []Suite{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		name, _ := filepath.Rel(dir, p)
+		name = filepath.ToSlash(name)
+		if !r.wants(name) {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		data, err := r.read(f)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", p, err)
+		}
+
+		s, err := r.readFile(name, data, 0)
+		if err != nil {
+			return err
+		}
+
+		suites = append(suites, s...)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	suites := []junit.Suite{}
+	return suites, nil
+}
+
+// wants reports whether the file at name, a path as passed to readFile, should be read at all: it is an archive the
+// SDK doesn't exclude, or it is included by the SDK and has an extension of its result format. Include globs only
+// apply to result files, so archives are opened to look for them. Anything else, such as coverage reports, logs or
+// binaries, is skipped without reading it, so it doesn't count against maxArtifactSize.
+func (r *artifactReader) wants(name string) bool {
+	if archiveKind(name) != "" {
+		return !r.sdk.excludes(name)
+	}
+
+	return r.sdk.includes(name) && hasResultExtension(name, r.sdk.Format)
+}
+
+// readFile reads a single file at depth levels of archive nesting. name is the file's path within the artifact, with
+// the paths of any enclosing archives as prefixes, such as "results.tar.gz/module/TEST-foo.xml".
+func (r *artifactReader) readFile(name string, data []byte, depth int) ([]Suite, error) {
+	if kind := archiveKind(name); kind != "" {
+		if depth >= maxArchiveDepth {
+			slog.Warn("skipping nested archive, too deep", "sdk", r.sdk.Name, "file", name, "max_depth", maxArchiveDepth)
+			return nil, nil
+		}
+
+		switch kind {
+		case "zip":
+			return r.readZip(name, data, depth+1)
+		case "tar":
+			return r.readTar(name, bytes.NewReader(data), depth+1)
+		case "tar.gz":
+			gz, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("error reading %s: %v", name, err)
+			}
+			defer gz.Close()
+			return r.readTar(name, gz, depth+1)
+		}
+	}
+
+	if !r.sdk.includes(name) {
+		return nil, nil
+	}
+
+	s, format, err := readResultFile(name, data, r.sdk.Format)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		return nil, nil
+	}
+
+	slog.Info("read", "suites", len(s), "file", name)
+
+	suites := make([]Suite, len(s))
+	for i := range s {
+		suites[i] = Suite{Suite: s[i], File: name, Format: format}
+	}
+
+	return suites, nil
+}
+
+func (r *artifactReader) readZip(name string, data []byte, depth int) ([]Suite, error) {
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		if name == "" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error reading %s: %v", name, err)
+	}
+
+	suites := []Suite{}
 	for _, f := range z.File {
-		if !strings.HasSuffix(f.Name, ".xml") {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		fileName := path.Join(name, f.Name)
+		if !r.wants(fileName) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", fileName, err)
+		}
+		data, err := r.read(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", fileName, err)
+		}
+
+		s, err := r.readFile(fileName, data, depth)
+		if err != nil {
+			return nil, err
+		}
+
+		suites = append(suites, s...)
+	}
+
+	return suites, nil
+}
+
+func (r *artifactReader) readTar(name string, reader io.Reader, depth int) ([]Suite, error) {
+	t := tar.NewReader(reader)
+
+	suites := []Suite{}
+	for {
+		header, err := t.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", name, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		fileName := path.Join(name, header.Name)
+		if !r.wants(fileName) {
 			continue
 		}
 
-		s, err := readJunit(f)
+		data, err := r.read(t)
 		if err != nil {
-			return nil, fmt.Errorf("error reading %s: %v", f.Name, err)
+			return nil, fmt.Errorf("error reading %s: %v", fileName, err)
 		}
 
-		slog.Info("read", "suites", len(s), "file", f.Name)
+		s, err := r.readFile(fileName, data, depth)
+		if err != nil {
+			return nil, err
+		}
 
 		suites = append(suites, s...)
 	}
@@ -35,17 +219,102 @@ func readArtifactZip(artifact []byte) ([]junit.Suite, error) {
 	return suites, nil
 }
 
-func readJunit(f *zip.File) ([]junit.Suite, error) {
-	r, err := f.Open()
+// read reads all of reader, failing once the artifact's size limit is used up.
+func (r *artifactReader) read(reader io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, r.remaining+1))
 	if err != nil {
-		return []junit.Suite{}, err
+		return nil, err
 	}
-	defer r.Close()
 
-	suites, err := junit.IngestReader(r)
-	if err != nil {
-		return []junit.Suite{}, err
+	if int64(len(data)) > r.remaining {
+		return nil, fmt.Errorf("artifact is larger than the %d byte limit when uncompressed", maxArtifactSize)
 	}
+	r.remaining -= int64(len(data))
 
-	return suites, nil
+	return data, nil
+}
+
+func archiveKind(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	default:
+		return ""
+	}
+}
+
+// includes reports whether a file in the SDK's artifact should be read, according to its Include and Exclude globs.
+func (s SDKMeta) includes(name string) bool {
+	if s.excludes(name) {
+		return false
+	}
+
+	if len(s.Include) == 0 {
+		return true
+	}
+
+	for _, pattern := range s.Include {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// excludes reports whether a file or archive in the SDK's artifact matches one of its Exclude globs.
+func (s SDKMeta) excludes(name string) bool {
+	for _, pattern := range s.Exclude {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlob matches a slash separated path against a pattern in path.Match syntax, where a "**" segment also matches
+// any number of whole path segments.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// validateGlob checks that every segment of pattern is valid path.Match syntax.
+func validateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %v", pattern, err)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,95 @@
+package reports
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+type testFile struct{ name, content string }
+
+func zipBytes(t *testing.T, files []testFile) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := z.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func tarGzBytes(t *testing.T, files []testFile) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestArtifactReaderSkipsOtherFiles(t *testing.T) {
+	result := `<testsuites><testsuite name="Web5TestVectorsDidJwk"><testcase name="resolve"/></testsuite></testsuites>`
+
+	artifact := zipBytes(t, []testFile{
+		{"TEST-DidJwk.xml", result},
+		{"coverage/lcov.info", string(make([]byte, 1<<20))},
+		{"build.log", "lots of output"},
+		{"excluded/TEST-Other.xml", result},
+		// excluded archives are not opened, even though they hold result files
+		{"coverage.tar.gz", string(tarGzBytes(t, []testFile{{"TEST-Coverage.xml", result}}))},
+		// archives are opened whether or not they match the include globs, which are for the files inside them
+		{"nested/results.zip", string(zipBytes(t, []testFile{{"TEST-Nested.xml", result}}))},
+	})
+
+	sdk := NewSDKMeta("web5-test", "TBD54566975/web5-test", "test-results", "", "web5", nil, nil)
+	sdk.Include = []string{"**/TEST-*.xml"}
+	sdk.Exclude = []string{"excluded/**", "coverage.tar.gz"}
+
+	r := newArtifactReader(sdk)
+	suites, err := r.readZip("", artifact, 0)
+	if err != nil {
+		t.Fatalf("error reading artifact: %v", err)
+	}
+
+	var files []string
+	for _, s := range suites {
+		files = append(files, s.File)
+	}
+	if len(files) != 2 || files[0] != "TEST-DidJwk.xml" || files[1] != "nested/results.zip/TEST-Nested.xml" {
+		t.Errorf("got suites from %v, want TEST-DidJwk.xml and nested/results.zip/TEST-Nested.xml", files)
+	}
+
+	// only the result files and the archive holding one count against the size limit
+	nested := zipBytes(t, []testFile{{"TEST-Nested.xml", result}})
+	if read, want := maxArtifactSize-r.remaining, int64(2*len(result)+len(nested)); read != want {
+		t.Errorf("read %d bytes, want %d", read, want)
+	}
+}
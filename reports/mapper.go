@@ -0,0 +1,226 @@
+package reports
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	junit "github.com/joshdk/go-junit"
+)
+
+// Names of the built-in mapping strategies, as used in the "strategy" field of an SDK's mapper config.
+const (
+	MapperFeatureFromSuite     = "suite"
+	MapperFeatureFromTestName  = "test-name"
+	MapperFeatureFromClassname = "classname"
+	MapperPathSegment          = "path-segment"
+	MapperCustom               = "custom"
+)
+
+// Mapper works out which test vector a junit test case exercises. ok is false if the test case could not be mapped
+// to a feature and vector.
+type Mapper interface {
+	Map(suite junit.Suite, test junit.Test) (feature string, vector string, ok bool)
+}
+
+// MapperFunc adapts an ordinary function to the Mapper interface.
+type MapperFunc func(suite junit.Suite, test junit.Test) (feature string, vector string, ok bool)
+
+func (f MapperFunc) Map(suite junit.Suite, test junit.Test) (string, string, bool) {
+	return f(suite, test)
+}
+
+var (
+	customMappersLock sync.RWMutex
+	customMappers     = make(map[string]Mapper)
+)
+
+// RegisterMapper makes a custom Mapper available to SDK configs under the given name, using
+// {"strategy": "custom", "name": "<name>"}. It must be called before the config is loaded.
+func RegisterMapper(name string, mapper Mapper) {
+	customMappersLock.Lock()
+	defer customMappersLock.Unlock()
+
+	customMappers[name] = mapper
+}
+
+func getCustomMapper(name string) (Mapper, bool) {
+	customMappersLock.RLock()
+	defer customMappersLock.RUnlock()
+
+	mapper, ok := customMappers[name]
+	return mapper, ok
+}
+
+// FeatureFromSuite takes the feature from the first capture group of FeatureRegex applied to the suite name, and the
+// vector from the last capture group of VectorRegex applied to the test name. This is the layout most SDKs use.
+type FeatureFromSuite struct {
+	FeatureRegex *regexp.Regexp
+	VectorRegex  *regexp.Regexp
+}
+
+func (m FeatureFromSuite) Map(suite junit.Suite, test junit.Test) (string, string, bool) {
+	return mapWithRegexes(suite.Name, test.Name, m.FeatureRegex, m.VectorRegex, 1, false)
+}
+
+// FeatureFromTestName takes both the feature and the vector from the test name, for SDKs that put every vector test
+// in a single suite. The feature is read from capture group FeatureGroup of FeatureRegex.
+type FeatureFromTestName struct {
+	FeatureRegex *regexp.Regexp
+	VectorRegex  *regexp.Regexp
+	FeatureGroup int
+	CamelCase    bool
+}
+
+func (m FeatureFromTestName) Map(_ junit.Suite, test junit.Test) (string, string, bool) {
+	return mapWithRegexes(test.Name, test.Name, m.FeatureRegex, m.VectorRegex, m.FeatureGroup, m.CamelCase)
+}
+
+// FeatureFromClassname takes the feature from the test case's classname attribute and the vector from the test name.
+type FeatureFromClassname struct {
+	FeatureRegex *regexp.Regexp
+	VectorRegex  *regexp.Regexp
+	FeatureGroup int
+	CamelCase    bool
+}
+
+func (m FeatureFromClassname) Map(_ junit.Suite, test junit.Test) (string, string, bool) {
+	return mapWithRegexes(test.Classname, test.Name, m.FeatureRegex, m.VectorRegex, m.FeatureGroup, m.CamelCase)
+}
+
+// PathSegment splits the test name on Separator and picks the feature and vector out by index. Negative indexes count
+// back from the last segment, so -1 is the last segment.
+type PathSegment struct {
+	Separator      string
+	FeatureSegment int
+	VectorSegment  int
+	CamelCase      bool
+}
+
+func (m PathSegment) Map(_ junit.Suite, test junit.Test) (string, string, bool) {
+	segments := strings.Split(test.Name, m.Separator)
+
+	feature, ok := segmentAt(segments, m.FeatureSegment)
+	if !ok {
+		return "", "", false
+	}
+
+	vector, ok := segmentAt(segments, m.VectorSegment)
+	if !ok {
+		return "", "", false
+	}
+
+	if m.CamelCase {
+		feature = toCamelCase(feature)
+	}
+
+	return feature, vector, true
+}
+
+func segmentAt(segments []string, i int) (string, bool) {
+	if i < 0 {
+		i = len(segments) + i
+	}
+
+	if i < 0 || i >= len(segments) || segments[i] == "" {
+		return "", false
+	}
+
+	return segments[i], true
+}
+
+func mapWithRegexes(featureInput, vectorInput string, featureRegex, vectorRegex *regexp.Regexp, featureGroup int, camelCase bool) (string, string, bool) {
+	if featureGroup == 0 {
+		featureGroup = 1
+	}
+
+	matches := featureRegex.FindStringSubmatch(featureInput)
+	if len(matches) <= featureGroup {
+		return "", "", false
+	}
+
+	feature := matches[featureGroup]
+	if camelCase {
+		feature = toCamelCase(feature)
+	}
+
+	vector := extractTestName(vectorInput, vectorRegex)
+	if feature == "" || vector == "" {
+		return "", "", false
+	}
+
+	return feature, vector, true
+}
+
+// mapperConfig selects and configures the Mapper for an SDK. Which fields apply depends on the strategy.
+type mapperConfig struct {
+	Strategy       string `json:"strategy"`
+	FeatureGroup   int    `json:"featureGroup,omitempty"`
+	CamelCase      bool   `json:"camelCase,omitempty"`
+	Separator      string `json:"separator,omitempty"`
+	FeatureSegment int    `json:"featureSegment,omitempty"`
+	VectorSegment  int    `json:"vectorSegment,omitempty"`
+	Name           string `json:"name,omitempty"`
+}
+
+// usesRegexes reports whether the strategy reads the SDK's featureRegex and vectorRegex.
+func (c *mapperConfig) usesRegexes() bool {
+	if c == nil {
+		return true
+	}
+
+	switch c.Strategy {
+	case MapperPathSegment, MapperCustom:
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *mapperConfig) build(featureRegex, vectorRegex *regexp.Regexp) (Mapper, error) {
+	if c == nil {
+		return FeatureFromSuite{FeatureRegex: featureRegex, VectorRegex: vectorRegex}, nil
+	}
+
+	if c.FeatureGroup < 0 {
+		return nil, fmt.Errorf("mapper featureGroup must not be negative")
+	}
+
+	switch c.Strategy {
+	case "", MapperFeatureFromSuite:
+		return FeatureFromSuite{FeatureRegex: featureRegex, VectorRegex: vectorRegex}, nil
+	case MapperFeatureFromTestName:
+		return FeatureFromTestName{
+			FeatureRegex: featureRegex,
+			VectorRegex:  vectorRegex,
+			FeatureGroup: c.FeatureGroup,
+			CamelCase:    c.CamelCase,
+		}, nil
+	case MapperFeatureFromClassname:
+		return FeatureFromClassname{
+			FeatureRegex: featureRegex,
+			VectorRegex:  vectorRegex,
+			FeatureGroup: c.FeatureGroup,
+			CamelCase:    c.CamelCase,
+		}, nil
+	case MapperPathSegment:
+		if c.Separator == "" {
+			return nil, fmt.Errorf("mapper separator is required for the %s strategy", MapperPathSegment)
+		}
+		return PathSegment{
+			Separator:      c.Separator,
+			FeatureSegment: c.FeatureSegment,
+			VectorSegment:  c.VectorSegment,
+			CamelCase:      c.CamelCase,
+		}, nil
+	case MapperCustom:
+		mapper, ok := getCustomMapper(c.Name)
+		if !ok {
+			return nil, fmt.Errorf("no custom mapper registered with name %q", c.Name)
+		}
+		return mapper, nil
+	default:
+		return nil, fmt.Errorf("unknown mapper strategy %q", c.Strategy)
+	}
+}
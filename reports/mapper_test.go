@@ -0,0 +1,225 @@
+package reports
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	junit "github.com/joshdk/go-junit"
+)
+
+func TestMappers(t *testing.T) {
+	tests := []struct {
+		name   string
+		mapper Mapper
+		suite  string
+		test   junit.Test
+
+		wantFeature string
+		wantVector  string
+		wantOK      bool
+	}{
+		{
+			name:        "suite",
+			mapper:      FeatureFromSuite{FeatureRegex: regexp.MustCompile(`Web5TestVectors(\w+)`), VectorRegex: regexp.MustCompile(`test_(\w+)`)},
+			suite:       "Web5TestVectorsDidJwk",
+			test:        junit.Test{Name: "test_resolve"},
+			wantFeature: "DidJwk", wantVector: "resolve", wantOK: true,
+		},
+		{
+			name:   "suite not matching",
+			mapper: FeatureFromSuite{FeatureRegex: regexp.MustCompile(`Web5TestVectors(\w+)`), VectorRegex: regexp.MustCompile(`(\w+)`)},
+			suite:  "CryptoTest",
+			test:   junit.Test{Name: "sign"},
+		},
+		{
+			name: "test name, later group, camel-cased",
+			mapper: FeatureFromTestName{
+				FeatureRegex: regexp.MustCompile(`::(\w+)::(\w+)::(\w+)`),
+				VectorRegex:  regexp.MustCompile(`::(\w+)$`),
+				FeatureGroup: 2,
+				CamelCase:    true,
+			},
+			suite:       "web5",
+			test:        junit.Test{Name: "web5::test_vectors::did_jwk::resolve"},
+			wantFeature: "DidJwk", wantVector: "resolve", wantOK: true,
+		},
+		{
+			name: "test name, feature group out of range",
+			mapper: FeatureFromTestName{
+				FeatureRegex: regexp.MustCompile(`::(\w+)::`),
+				VectorRegex:  regexp.MustCompile(`::(\w+)$`),
+				FeatureGroup: 2,
+			},
+			test: junit.Test{Name: "web5::did_jwk::resolve"},
+		},
+		{
+			name: "test name, default group",
+			mapper: FeatureFromTestName{
+				FeatureRegex: regexp.MustCompile(`^(\w+)/`),
+				VectorRegex:  regexp.MustCompile(`/(\w+)$`),
+			},
+			test:        junit.Test{Name: "did_jwk/resolve"},
+			wantFeature: "did_jwk", wantVector: "resolve", wantOK: true,
+		},
+		{
+			name: "classname",
+			mapper: FeatureFromClassname{
+				FeatureRegex: regexp.MustCompile(`\.(\w+)Test$`),
+				VectorRegex:  regexp.MustCompile(`(\w+)`),
+			},
+			suite:       "web5.tests",
+			test:        junit.Test{Name: "resolve", Classname: "web5.dids.DidJwkTest"},
+			wantFeature: "DidJwk", wantVector: "resolve", wantOK: true,
+		},
+		{
+			name: "classname without a match",
+			mapper: FeatureFromClassname{
+				FeatureRegex: regexp.MustCompile(`\.(\w+)Test$`),
+				VectorRegex:  regexp.MustCompile(`(\w+)`),
+			},
+			test: junit.Test{Name: "resolve"},
+		},
+		{
+			name:        "path segment",
+			mapper:      PathSegment{Separator: " > ", FeatureSegment: 1, VectorSegment: -1, CamelCase: true},
+			test:        junit.Test{Name: "Web5TestVectors > did_jwk > resolve"},
+			wantFeature: "DidJwk", wantVector: "resolve", wantOK: true,
+		},
+		{
+			name:   "path segment out of range",
+			mapper: PathSegment{Separator: " > ", FeatureSegment: 1, VectorSegment: 3},
+			test:   junit.Test{Name: "Web5TestVectors > did_jwk > resolve"},
+		},
+		{
+			name:   "path segment empty",
+			mapper: PathSegment{Separator: "/", FeatureSegment: 0, VectorSegment: -1},
+			test:   junit.Test{Name: "did_jwk/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feature, vector, ok := tt.mapper.Map(junit.Suite{Name: tt.suite}, tt.test)
+			if feature != tt.wantFeature || vector != tt.wantVector || ok != tt.wantOK {
+				t.Errorf("got %q, %q, %t, want %q, %q, %t", feature, vector, ok, tt.wantFeature, tt.wantVector, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestToCamelCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"did_jwk":       "DidJwk",
+		"crypto_es256k": "CryptoEs256k",
+		"DidJwk":        "DidJwk",
+		"":              "",
+	} {
+		if got := toCamelCase(in); got != want {
+			t.Errorf("%q: got %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMapperConfig(t *testing.T) {
+	featureRegex, vectorRegex := regexp.MustCompile(`(\w+)`), regexp.MustCompile(`(\w+)`)
+
+	tests := []struct {
+		config  *mapperConfig
+		want    Mapper
+		wantErr string
+	}{
+		{config: nil, want: FeatureFromSuite{FeatureRegex: featureRegex, VectorRegex: vectorRegex}},
+		{config: &mapperConfig{Strategy: "suite"}, want: FeatureFromSuite{FeatureRegex: featureRegex, VectorRegex: vectorRegex}},
+		{
+			config: &mapperConfig{Strategy: "test-name", FeatureGroup: 2, CamelCase: true},
+			want:   FeatureFromTestName{FeatureRegex: featureRegex, VectorRegex: vectorRegex, FeatureGroup: 2, CamelCase: true},
+		},
+		{
+			config: &mapperConfig{Strategy: "classname", CamelCase: true},
+			want:   FeatureFromClassname{FeatureRegex: featureRegex, VectorRegex: vectorRegex, CamelCase: true},
+		},
+		{
+			config: &mapperConfig{Strategy: "path-segment", Separator: "::", FeatureSegment: 1, VectorSegment: -1},
+			want:   PathSegment{Separator: "::", FeatureSegment: 1, VectorSegment: -1},
+		},
+		{config: &mapperConfig{Strategy: "path-segment"}, wantErr: "separator is required"},
+		{config: &mapperConfig{Strategy: "test-name", FeatureGroup: -1}, wantErr: "must not be negative"},
+		{config: &mapperConfig{Strategy: "custom", Name: "not-registered"}, wantErr: `no custom mapper registered with name "not-registered"`},
+		{config: &mapperConfig{Strategy: "guess"}, wantErr: `unknown mapper strategy "guess"`},
+	}
+
+	for _, tt := range tests {
+		mapper, err := tt.config.build(featureRegex, vectorRegex)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%+v: got error %v, want %q", tt.config, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: unexpected error: %v", tt.config, err)
+			continue
+		}
+		if mapper != tt.want {
+			t.Errorf("%+v: got %#v, want %#v", tt.config, mapper, tt.want)
+		}
+	}
+}
+
+func TestRegisterMapper(t *testing.T) {
+	RegisterMapper("test-upper", MapperFunc(func(_ junit.Suite, test junit.Test) (string, string, bool) {
+		feature, vector, ok := strings.Cut(test.Name, ".")
+		return strings.ToUpper(feature), vector, ok
+	}))
+	t.Cleanup(func() {
+		customMappersLock.Lock()
+		defer customMappersLock.Unlock()
+		delete(customMappers, "test-upper")
+	})
+
+	// custom mappers don't need the regexes
+	sdks, err := ParseSDKs(strings.NewReader(`{"sdks": [{"name": "web5-go", "repo": "TBD54566975/web5-go", "artifactName": "a",
+		"vectorPath": "v", "type": "web5", "mapper": {"strategy": "custom", "name": "test-upper"}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feature, vector, ok := sdks[0].Mapper.Map(junit.Suite{}, junit.Test{Name: "dids.resolve"})
+	if feature != "DIDS" || vector != "resolve" || !ok {
+		t.Errorf("got %q, %q, %t, want the registered mapper's result", feature, vector, ok)
+	}
+}
+
+func TestWeb5RsMapper(t *testing.T) {
+	sdks, err := LoadSDKs("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sdk SDKMeta
+	for _, s := range sdks {
+		if s.Name == "web5-rs" {
+			sdk = s
+		}
+	}
+	if sdk.Mapper == nil {
+		t.Fatal("web5-rs is not in the default registry")
+	}
+
+	tests := []struct {
+		test                    string
+		wantFeature, wantVector string
+		wantOK                  bool
+	}{
+		{"web5::test_vectors::did_jwk::resolve", "DidJwk", "resolve", true},
+		{"crypto::test_vectors::crypto_es256k::verify_signature", "CryptoEs256k", "verify_signature", true},
+		{"resolve", "", "", false},
+	}
+	for _, tt := range tests {
+		feature, vector, ok := sdk.Mapper.Map(junit.Suite{Name: "web5"}, junit.Test{Name: tt.test})
+		if feature != tt.wantFeature || vector != tt.wantVector || ok != tt.wantOK {
+			t.Errorf("%s: got %q, %q, %t, want %q, %q, %t", tt.test, feature, vector, ok, tt.wantFeature, tt.wantVector, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package reports
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergePolicy decides an SDK's result for a vector when its artifact has several test runs of it, such as from a
+// matrix build across OS or runtime versions, or from retries of a flaky test.
+type MergePolicy string
+
+const (
+	// MergeFailIfAnyFailed fails the vector if any run failed or errored.
+	MergeFailIfAnyFailed MergePolicy = "fail-if-any-failed"
+
+	// MergePassIfAnyPassed passes the vector if any run passed, for SDKs that retry flaky tests.
+	MergePassIfAnyPassed MergePolicy = "pass-if-any-passed"
+
+	// MergePerPlatform treats the runs on each platform as retries, which pass if any run passed, and fails the vector
+	// if it did not pass on every platform. Runs with no platform are grouped by the result file they came from.
+	MergePerPlatform MergePolicy = "per-platform"
+)
+
+func parseMergePolicy(s string) (MergePolicy, error) {
+	switch policy := MergePolicy(s); policy {
+	case "":
+		return MergeFailIfAnyFailed, nil
+	case MergeFailIfAnyFailed, MergePassIfAnyPassed, MergePerPlatform:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown merge policy %q, expected %s, %s or %s", s, MergeFailIfAnyFailed, MergePassIfAnyPassed, MergePerPlatform)
+	}
+}
+
+// Run is a single execution of a vector's test.
+type Run struct {
+	// File is the path, within the artifact, of the result file the run was read from.
+	File string
+
+	// Platform is the platform the run was on, or empty if it isn't known, see Platforms.
+	Platform string
+
+	Status Status
+	Time   time.Duration
+
+	// Failure is set if the run failed or errored.
+	Failure *Failure
+}
+
+// statusSeverity orders statuses from best to worst, for picking the worst of several runs.
+var statusSeverity = map[Status]int{
+	StatusSkipped: 1,
+	StatusPassed:  2,
+	StatusUnknown: 3,
+	StatusErrored: 4,
+	StatusFailed:  5,
+}
+
+// mergeRuns combines every run of a vector into one result according to policy.
+func mergeRuns(policy MergePolicy, runs []Run) Result {
+	result := Result{
+		Status:    mergeStatus(policy, runs),
+		Failures:  []Failure{},
+		Runs:      runs,
+		Platforms: platformResults(policy, runs),
+	}
+	for _, run := range runs {
+		if run.Time > result.Time {
+			result.Time = run.Time
+		}
+		if run.Failure != nil {
+			result.Failures = append(result.Failures, *run.Failure)
+		}
+	}
+
+	return result
+}
+
+// mergeStatus is the status of a vector according to policy, given every run of it.
+func mergeStatus(policy MergePolicy, runs []Run) Status {
+	switch policy {
+	case MergePassIfAnyPassed:
+		return passIfAnyPassed(runs)
+	case MergePerPlatform:
+		var platforms []string
+		byPlatform := make(map[string][]Run)
+		for _, run := range runs {
+			platform := run.Platform
+			if platform == "" {
+				platform = run.File
+			}
+			if _, ok := byPlatform[platform]; !ok {
+				platforms = append(platforms, platform)
+			}
+			byPlatform[platform] = append(byPlatform[platform], run)
+		}
+
+		perPlatform := make([]Run, len(platforms))
+		for i, platform := range platforms {
+			perPlatform[i] = Run{Status: passIfAnyPassed(byPlatform[platform])}
+		}
+		return worstStatus(perPlatform)
+	default:
+		return worstStatus(runs)
+	}
+}
+
+// PlatformResult is the merged result of a vector's runs on one platform.
+type PlatformResult struct {
+	Platform string
+	Status   Status
+	Runs     int
+}
+
+func (p PlatformResult) GetEmoji() string {
+	return Result{Status: p.Status}.GetEmoji()
+}
+
+func (p PlatformResult) GetEmojiAriaLabel() string {
+	return Result{Status: p.Status}.GetEmojiAriaLabel()
+}
+
+// platformResults merges the runs on each platform with policy, sorted by platform. Runs with no platform are left
+// out, and the result is empty if no run has a platform.
+func platformResults(policy MergePolicy, runs []Run) []PlatformResult {
+	byPlatform := make(map[string][]Run)
+	for _, run := range runs {
+		if run.Platform != "" {
+			byPlatform[run.Platform] = append(byPlatform[run.Platform], run)
+		}
+	}
+
+	results := make([]PlatformResult, 0, len(byPlatform))
+	for platform, platformRuns := range byPlatform {
+		results = append(results, PlatformResult{
+			Platform: platform,
+			Status:   mergeStatus(policy, platformRuns),
+			Runs:     len(platformRuns),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Platform < results[j].Platform
+	})
+
+	return results
+}
+
+func worstStatus(runs []Run) Status {
+	worst := runs[0].Status
+	for _, run := range runs[1:] {
+		if statusSeverity[run.Status] > statusSeverity[worst] {
+			worst = run.Status
+		}
+	}
+
+	return worst
+}
+
+func passIfAnyPassed(runs []Run) Status {
+	for _, run := range runs {
+		if run.Status == StatusPassed {
+			return StatusPassed
+		}
+	}
+
+	return worstStatus(runs)
+}
+
+// RunSummary describes how many runs a result was aggregated from and their outcomes, such as "3 runs: 2 passed,
+// 1 failed". It is empty for results from a single run.
+func (r Result) RunSummary() string {
+	if len(r.Runs) < 2 {
+		return ""
+	}
+
+	counts := make(map[Status]int)
+	for _, run := range r.Runs {
+		counts[run.Status]++
+	}
+
+	statuses := make([]Status, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statusSeverity[statuses[i]] < statusSeverity[statuses[j]]
+	})
+
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = fmt.Sprintf("%d %s", counts[status], status)
+	}
+
+	return fmt.Sprintf("%d runs: %s", len(r.Runs), strings.Join(parts, ", "))
+}
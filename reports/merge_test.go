@@ -0,0 +1,149 @@
+package reports
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeStatus(t *testing.T) {
+	run := func(platform, file string, status Status) Run {
+		return Run{Platform: platform, File: file, Status: status}
+	}
+
+	tests := []struct {
+		name string
+		runs []Run
+
+		// wanted status for fail-if-any-failed, pass-if-any-passed and per-platform
+		failIfAnyFailed Status
+		passIfAnyPassed Status
+		perPlatform     Status
+	}{
+		{
+			name:            "single pass",
+			runs:            []Run{run("", "a.xml", StatusPassed)},
+			failIfAnyFailed: StatusPassed, passIfAnyPassed: StatusPassed, perPlatform: StatusPassed,
+		},
+		{
+			name:            "retried in the same file",
+			runs:            []Run{run("", "a.xml", StatusFailed), run("", "a.xml", StatusPassed)},
+			failIfAnyFailed: StatusFailed, passIfAnyPassed: StatusPassed, perPlatform: StatusPassed,
+		},
+		{
+			name:            "failed in one file, passed in another",
+			runs:            []Run{run("", "a.xml", StatusFailed), run("", "b.xml", StatusPassed)},
+			failIfAnyFailed: StatusFailed, passIfAnyPassed: StatusPassed, perPlatform: StatusFailed,
+		},
+		{
+			name:            "passed beats skipped",
+			runs:            []Run{run("", "a.xml", StatusSkipped), run("", "a.xml", StatusPassed)},
+			failIfAnyFailed: StatusPassed, passIfAnyPassed: StatusPassed, perPlatform: StatusPassed,
+		},
+		{
+			name:            "all skipped",
+			runs:            []Run{run("", "a.xml", StatusSkipped), run("", "b.xml", StatusSkipped)},
+			failIfAnyFailed: StatusSkipped, passIfAnyPassed: StatusSkipped, perPlatform: StatusSkipped,
+		},
+		{
+			name:            "unknown is worse than passed",
+			runs:            []Run{run("", "a.xml", StatusPassed), run("", "a.xml", StatusUnknown)},
+			failIfAnyFailed: StatusUnknown, passIfAnyPassed: StatusPassed, perPlatform: StatusPassed,
+		},
+		{
+			name:            "errored and passed",
+			runs:            []Run{run("", "a.xml", StatusErrored), run("", "b.xml", StatusPassed)},
+			failIfAnyFailed: StatusErrored, passIfAnyPassed: StatusPassed, perPlatform: StatusErrored,
+		},
+		{
+			name:            "failed is worse than errored",
+			runs:            []Run{run("", "a.xml", StatusErrored), run("", "a.xml", StatusFailed)},
+			failIfAnyFailed: StatusFailed, passIfAnyPassed: StatusFailed, perPlatform: StatusFailed,
+		},
+		{
+			name: "retry passed on every platform",
+			runs: []Run{
+				run("linux", "a.xml", StatusFailed), run("linux", "a.xml", StatusPassed),
+				run("macos", "b.xml", StatusPassed),
+			},
+			failIfAnyFailed: StatusFailed, passIfAnyPassed: StatusPassed, perPlatform: StatusPassed,
+		},
+		{
+			name: "failed on one platform",
+			runs: []Run{
+				run("linux", "a.xml", StatusPassed),
+				run("macos", "b.xml", StatusFailed), run("macos", "b.xml", StatusErrored),
+			},
+			failIfAnyFailed: StatusFailed, passIfAnyPassed: StatusPassed, perPlatform: StatusFailed,
+		},
+		{
+			name: "same platform in several files",
+			runs: []Run{
+				run("linux", "a.xml", StatusFailed), run("linux", "b.xml", StatusPassed),
+			},
+			failIfAnyFailed: StatusFailed, passIfAnyPassed: StatusPassed, perPlatform: StatusPassed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := map[MergePolicy]Status{
+				MergeFailIfAnyFailed: tt.failIfAnyFailed,
+				MergePassIfAnyPassed: tt.passIfAnyPassed,
+				MergePerPlatform:     tt.perPlatform,
+			}
+			for policy, status := range want {
+				if got := mergeStatus(policy, tt.runs); got != status {
+					t.Errorf("%s: got %s, want %s", policy, got, status)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusSeverity(t *testing.T) {
+	bestToWorst := []Status{StatusSkipped, StatusPassed, StatusUnknown, StatusErrored, StatusFailed}
+	for i := 1; i < len(bestToWorst); i++ {
+		better, worse := bestToWorst[i-1], bestToWorst[i]
+		if statusSeverity[better] >= statusSeverity[worse] {
+			t.Errorf("%s should be less severe than %s", better, worse)
+		}
+		if got := worstStatus([]Run{{Status: worse}, {Status: better}}); got != worse {
+			t.Errorf("worst of %s and %s: got %s", worse, better, got)
+		}
+	}
+}
+
+func TestMergeRuns(t *testing.T) {
+	failure := &Failure{Message: "boom"}
+	runs := []Run{
+		{Platform: "linux", Status: StatusPassed, Time: 2 * time.Second},
+		{Platform: "linux", Status: StatusFailed, Time: time.Second, Failure: failure},
+		{Platform: "macos", Status: StatusFailed, Time: 3 * time.Second, Failure: failure},
+	}
+
+	result := mergeRuns(MergePassIfAnyPassed, runs)
+	if result.Status != StatusPassed {
+		t.Errorf("got status %s, want passed", result.Status)
+	}
+	if result.Time != 3*time.Second {
+		t.Errorf("got time %s, want the longest run, 3s", result.Time)
+	}
+	if len(result.Failures) != 2 {
+		t.Errorf("got %d failures, want one per failed run", len(result.Failures))
+	}
+
+	// each platform is merged with the same policy, sorted by platform
+	want := []PlatformResult{{Platform: "linux", Status: StatusPassed, Runs: 2}, {Platform: "macos", Status: StatusFailed, Runs: 1}}
+	if len(result.Platforms) != len(want) {
+		t.Fatalf("got platforms %+v, want %+v", result.Platforms, want)
+	}
+	for i := range want {
+		if result.Platforms[i] != want[i] {
+			t.Errorf("got platform %+v, want %+v", result.Platforms[i], want[i])
+		}
+	}
+
+	if got, want := result.RunSummary(), "3 runs: 1 passed, 2 failed"; got != want {
+		t.Errorf("got run summary %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	junit "github.com/joshdk/go-junit"
+)
+
+// Platforms describes how to tell which platform, such as an OS or runtime target, each test run was on. The first of
+// these that gives a platform for a run is used. Runs with no platform are only counted in the SDK-level result.
+type Platforms struct {
+	// Artifacts maps platform name to the name of the artifact holding that platform's results. When set, these
+	// artifacts are fetched instead of the SDK's ArtifactName.
+	Artifacts map[string]string
+
+	// Property is the name of a junit property, on the test case or its suite, holding the platform.
+	Property string
+
+	// PathRegex is matched against the path of the result file within the artifact, and its first capture group is
+	// the platform.
+	PathRegex *regexp.Regexp
+}
+
+func (p Platforms) IsEmpty() bool {
+	return len(p.Artifacts) == 0 && p.Property == "" && p.PathRegex == nil
+}
+
+// platform works out which platform a test run was on, or returns "" if it can't.
+func (p Platforms) platform(suite Suite, test junit.Test) string {
+	if suite.Platform != "" {
+		return suite.Platform
+	}
+
+	if p.Property != "" {
+		if platform := test.Properties[p.Property]; platform != "" {
+			return platform
+		}
+		if platform := suite.Properties[p.Property]; platform != "" {
+			return platform
+		}
+	}
+
+	if p.PathRegex != nil {
+		if matches := p.PathRegex.FindStringSubmatch(suite.File); len(matches) > 1 {
+			return matches[1]
+		}
+	}
+
+	return ""
+}
+
+// platformNames lists the platforms with their own artifact, sorted.
+func (p Platforms) platformNames() []string {
+	names := make([]string, 0, len(p.Artifacts))
+	for name := range p.Artifacts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// fetchPlatformArtifacts fetches the artifact of each platform in sdk.Platforms.Artifacts with fetch, and combines
+// them into one artifact. The provenance is that of the oldest artifact, so the report is flagged as stale if any
+// platform's results are.
+func fetchPlatformArtifacts(ctx context.Context, sdk SDKMeta, fetch func(ctx context.Context, platform, artifactName string) (Artifact, error)) (Artifact, error) {
+	var combined Artifact
+	for i, platform := range sdk.Platforms.platformNames() {
+		artifact, err := fetch(ctx, platform, sdk.Platforms.Artifacts[platform])
+		if err != nil {
+			return Artifact{}, err
+		}
+
+		for _, suite := range artifact.Suites {
+			suite.Platform = platform
+			combined.Suites = append(combined.Suites, suite)
+		}
+
+		if i == 0 || artifact.Provenance.CreatedAt.Before(combined.Provenance.CreatedAt) {
+			combined.Provenance = artifact.Provenance
+		}
+	}
+
+	return combined, nil
+}
+
+// platformConfig is the on-disk format of Platforms.
+type platformConfig struct {
+	Artifacts map[string]string `json:"artifacts,omitempty"`
+	Property  string            `json:"property,omitempty"`
+	PathRegex string            `json:"pathRegex,omitempty"`
+}
+
+func (c *platformConfig) build() (Platforms, error) {
+	if c == nil {
+		return Platforms{}, nil
+	}
+
+	platforms := Platforms{
+		Artifacts: c.Artifacts,
+		Property:  c.Property,
+	}
+
+	for platform, artifactName := range c.Artifacts {
+		if platform == "" || artifactName == "" {
+			return Platforms{}, fmt.Errorf("platform artifacts must map a platform name to an artifact name")
+		}
+	}
+
+	if c.PathRegex != "" {
+		re, err := regexp.Compile(c.PathRegex)
+		if err != nil {
+			return Platforms{}, fmt.Errorf("invalid platform pathRegex: %v", err)
+		}
+		if re.NumSubexp() < 1 {
+			return Platforms{}, fmt.Errorf("platform pathRegex must have a capture group")
+		}
+		platforms.PathRegex = re
+	}
+
+	return platforms, nil
+}
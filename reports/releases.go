@@ -0,0 +1,256 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"golang.org/x/exp/slog"
+)
+
+// PinVectorSuite returns suite pinned to ref, a tag or commit of its vectors. If the suite's root is a checkout of
+// the suite's spec repo that has ref, the vectors are read from there. Otherwise they are fetched from the suite's Repo
+// with the GitHub contents API into a temporary directory, which needs gh. cleanup removes that directory; call it
+// once the pinned suite is no longer needed.
+func PinVectorSuite(ctx context.Context, gh *GitHub, suite VectorSuite, ref string) (pinned VectorSuite, cleanup func(), err error) {
+	pinned = suite
+	pinned.Version = ref
+
+	if dir, err := suite.dir(); err == nil && isSpecCheckout(dir, suite) && gitHasRef(dir, ref) {
+		slog.Info("reading vectors from git", "suite", suite.Name, "ref", ref, "dir", dir)
+		pinned.Ref = ref
+		return pinned, func() {}, nil
+	}
+
+	if suite.Repo == "" || suite.RepoPath == "" {
+		return VectorSuite{}, nil, fmt.Errorf("ref %s of vector suite %s is not in a local checkout of the spec repo, and the suite has no repo and repoPath to fetch it from", ref, suite.Name)
+	}
+	if gh == nil {
+		return VectorSuite{}, nil, fmt.Errorf("ref %s of vector suite %s is not in a local checkout of the spec repo, and fetching it from %s needs github credentials", ref, suite.Name, suite.Repo)
+	}
+
+	dir, err := os.MkdirTemp("", "vectors-"+suite.Name+"-")
+	if err != nil {
+		return VectorSuite{}, nil, fmt.Errorf("error making directory for vectors: %v", err)
+	}
+	cleanup = func() {
+		if err := os.RemoveAll(dir); err != nil {
+			slog.Warn("error removing vectors", "dir", dir, "error", err)
+		}
+	}
+
+	slog.Info("fetching vectors from github", "suite", suite.Name, "repo", suite.Repo, "path", suite.RepoPath, "ref", ref)
+	if err := downloadVectors(ctx, gh, suite, ref, dir); err != nil {
+		cleanup()
+		return VectorSuite{}, nil, err
+	}
+
+	pinned.Root = dir
+	pinned.Ref = ""
+
+	return pinned, cleanup, nil
+}
+
+// isSpecCheckout reports whether dir is in a checkout of the suite's spec repo: either it is the top of its git work
+// tree, or the work tree's origin is the suite's Repo. Vectors copied into another repo, such as this one, are not, as
+// refs there are that repo's own.
+func isSpecCheckout(dir string, suite VectorSuite) bool {
+	toplevel, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return false
+	}
+
+	if sameDir(toplevel, dir) {
+		return true
+	}
+
+	origin, err := gitOutput(dir, "remote", "get-url", "origin")
+	if err != nil || suite.Repo == "" {
+		return false
+	}
+
+	// https://github.com/owner/name.git or git@github.com:owner/name.git
+	origin = strings.ToLower(strings.TrimSuffix(origin, ".git"))
+	repo := strings.ToLower(suite.Repo)
+	return strings.HasSuffix(origin, "/"+repo) || strings.HasSuffix(origin, ":"+repo)
+}
+
+func sameDir(a, b string) bool {
+	a, errA := filepath.EvalSymlinks(a)
+	b, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && a == b
+}
+
+// gitOutput runs git in dir and returns its trimmed output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitHasRef reports whether dir is in a git repo with a commit for ref.
+func gitHasRef(dir, ref string) bool {
+	_, err := gitOutput(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return err == nil
+}
+
+// downloadVectors copies the files under suite.RepoPath in suite.Repo, as of ref, to dir.
+func downloadVectors(ctx context.Context, gh *GitHub, suite VectorSuite, ref, dir string) error {
+	owner, repo, _ := strings.Cut(suite.Repo, "/")
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+	root := strings.Trim(suite.RepoPath, "/")
+
+	var download func(repoPath string) error
+	download = func(repoPath string) error {
+		file, contents, _, err := gh.client.Repositories.GetContents(ctx, owner, repo, repoPath, opts)
+		if err != nil {
+			return fmt.Errorf("error getting %s from %s at %s: %v", repoPath, suite.Repo, ref, err)
+		}
+
+		if file != nil {
+			return downloadVectorFile(ctx, gh, owner, repo, file, opts, filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(repoPath, root+"/"))))
+		}
+
+		for _, entry := range contents {
+			switch entry.GetType() {
+			case "dir", "file":
+				if err := download(path.Join(repoPath, entry.GetName())); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	return download(root)
+}
+
+func downloadVectorFile(ctx context.Context, gh *GitHub, owner, repo string, file *github.RepositoryContent, opts *github.RepositoryContentGetOptions, dest string) error {
+	var data []byte
+	if file.GetEncoding() == "none" {
+		// the contents API leaves out the content of files over 1MB
+		r, _, err := gh.client.Repositories.DownloadContents(ctx, owner, repo, file.GetPath(), opts)
+		if err != nil {
+			return fmt.Errorf("error downloading %s: %v", file.GetPath(), err)
+		}
+		defer r.Close()
+
+		if data, err = io.ReadAll(r); err != nil {
+			return fmt.Errorf("error downloading %s: %v", file.GetPath(), err)
+		}
+	} else {
+		content, err := file.GetContent()
+		if err != nil {
+			return fmt.Errorf("error decoding %s: %v", file.GetPath(), err)
+		}
+		data = []byte(content)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("error making directory for %s: %v", file.GetPath(), err)
+	}
+
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", dest, err)
+	}
+
+	return nil
+}
+
+// ReleaseCompliance is how an SDK's latest results do against one release of its vector suite.
+type ReleaseCompliance struct {
+	SDK     string
+	Suite   string
+	Version string
+
+	Passing int
+	Total   int
+
+	// Unavailable is set if the SDK's results could not be fetched.
+	Unavailable bool
+}
+
+// IsCompliant reports whether the SDK passed every vector of the release.
+func (c ReleaseCompliance) IsCompliant() bool {
+	return !c.Unavailable && c.Total > 0 && c.Passing == c.Total
+}
+
+func (c ReleaseCompliance) GetEmoji() string {
+	switch {
+	case c.Unavailable:
+		return Result{Status: StatusUnavailable}.GetEmoji()
+	case c.IsCompliant():
+		return Result{Status: StatusPassed}.GetEmoji()
+	default:
+		return Result{Status: StatusFailed}.GetEmoji()
+	}
+}
+
+func (c ReleaseCompliance) GetEmojiAriaLabel() string {
+	switch {
+	case c.Unavailable:
+		return "Results unavailable"
+	case c.IsCompliant():
+		return "Compliant"
+	default:
+		return "Not compliant"
+	}
+}
+
+// ComplianceMatrix checks every report against each release, from PinVectorSuite, of the report's vector suite. The
+// reports' test results are matched against the release's vectors again, so vectors added or renamed since the
+// release don't count.
+func ComplianceMatrix(reports []Report, releases []VectorSuite) ([]ReleaseCompliance, error) {
+	var matrix []ReleaseCompliance
+	for _, release := range releases {
+		vectors, err := release.Vectors()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s vectors at %s: %v", release.Name, release.Version, err)
+		}
+
+		total := 0
+		for _, features := range vectors {
+			total += len(features)
+		}
+
+		for _, report := range reports {
+			if report.SDK.Type != release.Name {
+				continue
+			}
+
+			compliance := ReleaseCompliance{
+				SDK:         report.SDK.Name,
+				Suite:       release.Name,
+				Version:     release.Version,
+				Total:       total,
+				Unavailable: report.FetchError != nil,
+			}
+
+			if !compliance.Unavailable {
+				for _, features := range report.SDK.buildReportAgainst(report.suites, vectors).Results {
+					for _, result := range features {
+						if result.Status == StatusPassed {
+							compliance.Passing++
+						}
+					}
+				}
+			}
+
+			matrix = append(matrix, compliance)
+		}
+	}
+
+	return matrix, nil
+}
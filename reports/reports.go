@@ -2,24 +2,25 @@ package reports
 
 import (
 	"embed"
-	"errors"
+	"fmt"
 	htmltemplate "html/template"
 	"regexp"
 	"strings"
 	"time"
 
 	junit "github.com/joshdk/go-junit"
+	"golang.org/x/exp/slog"
 )
 
 var (
-	ErrNotSupported = errors.New("test not supported by this SDK")
-
 	//go:embed report-template.html
 	templatesFS embed.FS
 
 	htmlTemplates = htmltemplate.New("")
 	funcmap       = map[string]any{
 		"sanatizeHTML": sanatizeHTML,
+		"truncate":     truncateOutput,
+		"hasPrefix":    strings.HasPrefix,
 		"renderHTML": func(s string) htmltemplate.HTML { return htmltemplate.HTML(s) },
 	}
 )
@@ -39,6 +40,18 @@ type SDKMeta struct {
 	VectorRegex           *regexp.Regexp
 	VectorPath            string
 	Type                  string
+	Branch                string
+	Workflow              string
+	Event                 string
+	Conclusion            string
+	MaxAge                time.Duration
+	Format                ResultFormat
+	Merge                 MergePolicy
+	Platforms             Platforms
+	FlakyRuns             int
+	Include               []string
+	Exclude               []string
+	Mapper                Mapper
 	SubmoduleCommit       string
 	SubmoduleCommitBehind int
 }
@@ -52,30 +65,164 @@ func NewSDKMeta(name, repo, artifactName, vectorPath, sdkType string, featureReg
 		VectorRegex:           vectorRegex,
 		VectorPath:            vectorPath,
 		Type:                  sdkType,
+		Branch:                defaultBranch,
+		MaxAge:                defaultMaxAge,
+		Format:                FormatAuto,
+		Merge:                 MergeFailIfAnyFailed,
+		Mapper:                FeatureFromSuite{FeatureRegex: featureRegex, VectorRegex: vectorRegex},
 		SubmoduleCommit:       "-",
 		SubmoduleCommitBehind: -1,
 	}
 }
 
 type Report struct {
-	SDK     SDKMeta
-	Results map[string]map[string]Result
+	SDK        SDKMeta
+	Provenance Provenance
+
+	// Stale is set if the artifact the results came from is older than the SDK's MaxAge.
+	Stale bool
+
+	// FetchError is set if the SDK's results could not be fetched, in which case every vector is StatusUnavailable.
+	FetchError *FetchError
+
+	Results   map[string]map[string]Result
+	Unmatched []UnmatchedTest
+
+	// suites are the test vector suites the results were built from, kept to build them again against other releases
+	// of the vectors.
+	suites []Suite
+}
+
+// Age is how long ago the artifact the results came from was created, or 0 if that isn't known.
+func (r Report) Age(now time.Time) time.Duration {
+	if r.Provenance.CreatedAt.IsZero() {
+		return 0
+	}
+
+	return now.Sub(r.Provenance.CreatedAt)
+}
+
+// AgeDays is the artifact age in whole days, for display.
+func (r Report) AgeDays() int {
+	return int(r.Age(time.Now()) / (24 * time.Hour))
+}
+
+// CommitURL links to the SDK commit the report reflects, or is empty if the commit isn't known.
+func (r Report) CommitURL() string {
+	if r.Provenance.CommitSHA == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("https://github.com/%s/commit/%s", r.SDK.Repo, r.Provenance.CommitSHA)
+}
+
+// UnmatchedTest is a test case from a test vector suite that did not match any known vector. Feature and Vector hold
+// whatever the SDK's mapper extracted, and are empty if it could not extract anything.
+type UnmatchedTest struct {
+	Suite   string
+	Test    string
+	Feature string
+	Vector  string
+}
+
+func (u UnmatchedTest) Reason() string {
+	if u.Feature == "" && u.Vector == "" {
+		return "no feature or vector could be extracted"
+	}
+
+	if u.Feature == "" {
+		return "no feature could be extracted"
+	}
+
+	if u.Vector == "" {
+		return "no vector could be extracted"
+	}
+
+	return "no such test vector"
+}
+
+// Status is the outcome of a single test vector for an SDK.
+type Status string
+
+const (
+	// StatusPassed means the vector's test ran and succeeded.
+	StatusPassed Status = "passed"
+
+	// StatusFailed means the vector's test ran and an assertion failed.
+	StatusFailed Status = "failed"
+
+	// StatusErrored means the vector's test ran but did not complete, such as an uncaught exception.
+	StatusErrored Status = "errored"
+
+	// StatusSkipped means the SDK has a test for the vector, but it was skipped.
+	StatusSkipped Status = "skipped"
+
+	// StatusNotImplemented means the SDK has no test for the vector.
+	StatusNotImplemented Status = "not-implemented"
+
+	// StatusUnknown means the SDK has a test for the vector, but its junit status was not recognised.
+	StatusUnknown Status = "unknown"
+
+	// StatusUnavailable means the SDK's results could not be fetched, see Report.FetchError.
+	StatusUnavailable Status = "unavailable"
+)
+
+// statusFromJUnit maps a junit test case status onto a Status.
+func statusFromJUnit(status junit.Status) Status {
+	switch status {
+	case junit.StatusPassed:
+		return StatusPassed
+	case junit.StatusFailed:
+		return StatusFailed
+	case junit.StatusError:
+		return StatusErrored
+	case junit.StatusSkipped:
+		return StatusSkipped
+	default:
+		return StatusUnknown
+	}
+}
+
+// IsFailure reports whether the status counts against an SDK's compliance.
+func (s Status) IsFailure() bool {
+	return s == StatusFailed || s == StatusErrored
 }
 
 type Result struct {
-	Exists bool
-	Errors []error
+	Status Status
 	Time   time.Duration
+
+	// Failures are the details of every run that failed or errored.
+	Failures []Failure
+
+	// Runs are the test runs the result was merged from, see MergePolicy. It is empty if the SDK has no test for the
+	// vector.
+	Runs []Run
+
+	// Platforms breaks the result down by the platform the runs were on, if known, see Platforms.
+	Platforms []PlatformResult
+
+	// Flakiness is the vector's history over the SDK's recent workflow runs, or nil if flaky test detection is off.
+	Flakiness *Flakiness
+}
+
+func (r Result) IsFlaky() bool {
+	return r.Flakiness != nil && r.Flakiness.IsFlaky()
+}
+
+// HasDetails reports whether there's more to show about the result than its status.
+func (r Result) HasDetails() bool {
+	return len(r.Failures) > 0 || len(r.Platforms) > 0
 }
 
 func (r Report) IsPassing() bool {
+	if r.FetchError != nil {
+		return false
+	}
+
 	for _, results := range r.Results {
 		for _, result := range results {
-			if result.IsSkipped() {
-				continue
-			}
-
-			if len(result.Errors) > 0 {
+			if result.Status.IsFailure() {
 				return false
 			}
 		}
@@ -85,128 +232,133 @@ func (r Report) IsPassing() bool {
 }
 
 func (r Result) IsSkipped() bool {
-	return len(r.Errors) == 1 && r.Errors[0] == ErrNotSupported
+	return r.Status == StatusSkipped
 }
 
 func (r Result) GetEmoji() string {
-	if !r.Exists {
-		return "🚧"
-	}
-
-	if len(r.Errors) == 0 {
+	switch r.Status {
+	case StatusPassed:
 		return "✅"
+	case StatusFailed:
+		return "❌"
+	case StatusErrored:
+		return "💥"
+	case StatusSkipped:
+		return "⏭️"
+	case StatusUnknown:
+		return "❓"
+	case StatusUnavailable:
+		return "🚫"
+	default:
+		return "🚧"
 	}
-
-	return "❌"
 }
 
 func (r Result) GetEmojiAriaLabel() string {
-	if !r.Exists {
-		return "In progress"
-	}
-
-	if len(r.Errors) == 0 {
+	switch r.Status {
+	case StatusPassed:
 		return "Success"
+	case StatusFailed:
+		return "Failed"
+	case StatusErrored:
+		return "Errored"
+	case StatusSkipped:
+		return "Skipped"
+	case StatusUnknown:
+		return "Unknown"
+	case StatusUnavailable:
+		return "Results unavailable"
+	default:
+		return "In progress"
 	}
-
-	return "Failed"
 }
-func (s SDKMeta) buildReport(suites []junit.Suite) (Report, error) {
-	results := make(map[string]map[string]Result)
-	vectorsToUse := getKnownVectors(s.Type)
 
-	for feature, vectors := range vectorsToUse {
+// newResults has an entry for every known vector, all with the given status.
+func newResults(knownVectors map[string]map[string]Vector, status Status) map[string]map[string]Result {
+	results := make(map[string]map[string]Result)
+	for feature, vectors := range knownVectors {
 		results[feature] = make(map[string]Result)
 		for vector := range vectors {
-			results[feature][vector] = Result{}
+			results[feature][vector] = Result{Status: status}
 		}
 	}
 
-	for _, suite := range suites {
-		feature := extractFeature(suite.Name, s.FeatureRegex)
-
-		for _, test := range suite.Tests {
-			vector := extractTestName(test.Name, s.VectorRegex)
-
-			errs := []error{}
-			if test.Error != nil {
-				errs = append(errs, test.Error)
-			}
+	return results
+}
 
-			if vectorsToUse[feature][vector] {
-				results[feature][vector] = Result{
-					Exists: true,
-					Errors: errs,
-					Time:   test.Duration,
-				}
-			}
-		}
+// unavailableReport is the placeholder report for an SDK whose results could not be fetched.
+func (s SDKMeta) unavailableReport(fetchErr *FetchError) (Report, error) {
+	knownVectors, err := KnownVectors(s.Type)
+	if err != nil {
+		return Report{}, err
 	}
 
 	return Report{
-		SDK:     s,
-		Results: results,
+		SDK:        s,
+		FetchError: fetchErr,
+		Results:    newResults(knownVectors, StatusUnavailable),
 	}, nil
 }
 
-// The web5-rs junit xml file is not able to be formatted the same as the others, so we have to write a custom parser
-func (s SDKMeta) buildReportWeb5Rs(suites []junit.Suite) (Report, error) {
-	results := make(map[string]map[string]Result)
-	vectorsToUse := getKnownVectors(s.Type)
-
-	for feature, vectors := range vectorsToUse {
-		results[feature] = make(map[string]Result)
-		for vector := range vectors {
-			results[feature][vector] = Result{}
-		}
+func (s SDKMeta) buildReport(suites []Suite) (Report, error) {
+	vectorsToUse, err := KnownVectors(s.Type)
+	if err != nil {
+		return Report{}, err
 	}
 
-	var suite = suites[0]
-
-	for _, test := range suite.Tests {
-		featureSubstrings := s.FeatureRegex.FindStringSubmatch(test.Name)
-		if len(featureSubstrings) < 3 {
-			continue
-		}
+	report := s.buildReportAgainst(suites, vectorsToUse)
+	for _, u := range report.Unmatched {
+		slog.Warn("unmatched test case", "sdk", s.Name, "suite", u.Suite, "test", u.Test, "feature", u.Feature, "vector", u.Vector, "reason", u.Reason())
+	}
 
-		feature := featureSubstrings[2]
-		feature = toCamelCase(feature)
+	return report, nil
+}
 
-		vectorSubstrings := s.VectorRegex.FindStringSubmatch(test.Name)
-		if len(featureSubstrings) < 1 {
-			continue
-		}
+// buildReportAgainst builds the SDK's report from the test vector suites of its artifact, for the given known
+// vectors.
+func (s SDKMeta) buildReportAgainst(suites []Suite, vectorsToUse map[string]map[string]Vector) Report {
+	results := newResults(vectorsToUse, StatusNotImplemented)
 
-		vector := vectorSubstrings[len(vectorSubstrings)-1]
+	runs := make(map[string]map[string][]Run)
+	var unmatched []UnmatchedTest
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			feature, vector, ok := s.Mapper.Map(suite.Suite, test)
+			if _, known := vectorsToUse[feature][vector]; !ok || !known {
+				unmatched = append(unmatched, UnmatchedTest{
+					Suite:   suite.Name,
+					Test:    test.Name,
+					Feature: feature,
+					Vector:  vector,
+				})
+				continue
+			}
 
-		errs := []error{}
-		if test.Error != nil {
-			errs = append(errs, test.Error)
+			if runs[feature] == nil {
+				runs[feature] = make(map[string][]Run)
+			}
+			runs[feature][vector] = append(runs[feature][vector], Run{
+				File:     suite.File,
+				Platform: s.Platforms.platform(suite, test),
+				Status:   statusFromJUnit(test.Status),
+				Failure:  newFailure(test),
+				Time:     test.Duration,
+			})
 		}
+	}
 
-		if vectorsToUse[feature][vector] {
-			results[feature][vector] = Result{
-				Exists: true,
-				Errors: errs,
-				Time:   test.Duration,
-			}
+	for feature, vectors := range runs {
+		for vector, vectorRuns := range vectors {
+			results[feature][vector] = mergeRuns(s.Merge, vectorRuns)
 		}
 	}
 
 	return Report{
-		SDK:     s,
-		Results: results,
-	}, nil
-}
-
-func extractFeature(input string, featureRegex *regexp.Regexp) string {
-	matches := featureRegex.FindStringSubmatch(input)
-	// If a match is found, it will be in the second element of the 'matches' slice.
-	if len(matches) >= 2 {
-		return matches[1]
+		SDK:       s,
+		Results:   results,
+		Unmatched: unmatched,
+		suites:    suites,
 	}
-
-	return ""
 }
 
 func extractTestName(input string, testRegex *regexp.Regexp) string {
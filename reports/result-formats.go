@@ -0,0 +1,600 @@
+package reports
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	junit "github.com/joshdk/go-junit"
+)
+
+// ResultFormat is the format of the test result files in an SDK's artifact. Every format is read into the same junit
+// suite and test model.
+type ResultFormat string
+
+const (
+	// FormatAuto picks the format of each file from its extension and contents, skipping files that aren't recognised.
+	FormatAuto ResultFormat = "auto"
+
+	// FormatJUnit is JUnit XML.
+	FormatJUnit ResultFormat = "junit"
+
+	// FormatGoTestJSON is the output of go test -json, one event per line.
+	FormatGoTestJSON ResultFormat = "go-test-json"
+
+	// FormatTAP is the Test Anything Protocol, including indented subtests.
+	FormatTAP ResultFormat = "tap"
+
+	// FormatNextestJSON is the libtest JSON output of cargo test or cargo nextest, one event per line.
+	FormatNextestJSON ResultFormat = "nextest-json"
+
+	// FormatCTRF is a Common Test Report Format JSON document.
+	FormatCTRF ResultFormat = "ctrf"
+)
+
+// testNamedFormats are the formats whose suites are named after the test binary or file rather than anything the SDK's
+// tests choose, so vector tests have to be picked out by their test names instead.
+var testNamedFormats = map[ResultFormat]bool{
+	FormatTAP:         true,
+	FormatNextestJSON: true,
+}
+
+// resultFormatExtensions lists the file extensions read for each format. Other files in an artifact are ignored.
+var resultFormatExtensions = map[ResultFormat][]string{
+	FormatJUnit:       {".xml"},
+	FormatGoTestJSON:  {".json", ".jsonl", ".ndjson"},
+	FormatTAP:         {".tap", ".txt"},
+	FormatNextestJSON: {".json", ".jsonl", ".ndjson"},
+	FormatCTRF:        {".json"},
+}
+
+func parseResultFormat(s string) (ResultFormat, error) {
+	if s == "" {
+		return FormatAuto, nil
+	}
+
+	format := ResultFormat(s)
+	if format != FormatAuto && resultFormatExtensions[format] == nil {
+		return "", fmt.Errorf("unknown format %q, expected %s or one of %s", s, FormatAuto, strings.Join(sortedFormats(), ", "))
+	}
+
+	return format, nil
+}
+
+// readResultFile parses a single file from an artifact, returning the format it was read as. read is empty if the file
+// is not a test result file in the given format, or, for FormatAuto, in any recognised format.
+func readResultFile(name string, data []byte, format ResultFormat) (suites []junit.Suite, read ResultFormat, err error) {
+	if format == FormatAuto {
+		format = detectResultFormat(name, data)
+		if format == "" {
+			return nil, "", nil
+		}
+	} else if !hasResultExtension(name, format) {
+		return nil, "", nil
+	}
+
+	switch format {
+	case FormatJUnit:
+		suites, err = junit.Ingest(data)
+	case FormatGoTestJSON:
+		suites, err = parseGoTestJSON(data)
+	case FormatTAP:
+		suites, err = parseTAP(name, data)
+	case FormatNextestJSON:
+		suites, err = parseNextestJSON(name, data)
+	case FormatCTRF:
+		suites, err = parseCTRF(data)
+	default:
+		return nil, "", fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, format, fmt.Errorf("error parsing %s as %s: %v", name, format, err)
+	}
+
+	return suites, format, nil
+}
+
+// hasResultExtension reports whether name has one of the extensions read for format, or, for FormatAuto, for any
+// format.
+func hasResultExtension(name string, format ResultFormat) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for f, extensions := range resultFormatExtensions {
+		if format != FormatAuto && f != format {
+			continue
+		}
+		for _, e := range extensions {
+			if ext == e {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+var tapLine = regexp.MustCompile(`^\s*(TAP version \d+|1\.\.\d+|(not )?ok\b)`)
+
+// detectResultFormat works out the format of a file from its extension and contents, or returns "" if it isn't a
+// test result file.
+func detectResultFormat(name string, data []byte) ResultFormat {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".xml":
+		return FormatJUnit
+	case ".tap":
+		return FormatTAP
+	case ".txt":
+		if tapLine.Match(firstLine(data)) {
+			return FormatTAP
+		}
+	case ".json", ".jsonl", ".ndjson":
+		var ctrf struct {
+			Results *json.RawMessage `json:"results"`
+		}
+		if json.Unmarshal(data, &ctrf) == nil && ctrf.Results != nil {
+			return FormatCTRF
+		}
+
+		var event map[string]json.RawMessage
+		if json.Unmarshal(firstLine(data), &event) != nil {
+			return ""
+		}
+		if _, ok := event["Action"]; ok {
+			return FormatGoTestJSON
+		}
+		if _, ok := event["type"]; ok {
+			if _, ok := event["event"]; ok {
+				return FormatNextestJSON
+			}
+		}
+	}
+
+	return ""
+}
+
+func firstLine(data []byte) []byte {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line
+		}
+	}
+
+	return nil
+}
+
+// suiteBuilder collects tests into suites, keeping suites and tests in the order they were first seen.
+type suiteBuilder struct {
+	suites []junit.Suite
+	index  map[string]int
+}
+
+func (b *suiteBuilder) add(suite string, test junit.Test) {
+	if b.index == nil {
+		b.index = make(map[string]int)
+	}
+
+	i, ok := b.index[suite]
+	if !ok {
+		i = len(b.suites)
+		b.index[suite] = i
+		b.suites = append(b.suites, junit.Suite{Name: suite, Package: suite})
+	}
+
+	b.suites[i].Tests = append(b.suites[i].Tests, test)
+}
+
+func (b *suiteBuilder) build() []junit.Suite {
+	for i := range b.suites {
+		b.suites[i].Aggregate()
+	}
+
+	return b.suites
+}
+
+// goTestEvent is a line of go test -json output, see go doc test2json.
+type goTestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// parseGoTestJSON reads go test -json output. Each top-level test is a suite, named after the test, holding its
+// subtests, as SDKs run a feature's vectors as subtests of one test such as TestTbdexTestVectorsProtocol. A test that
+// has subtests is only the summary of its suite, so it is skipped; a top-level test without any is its own suite's only
+// test.
+func parseGoTestJSON(data []byte) ([]junit.Suite, error) {
+	type key struct{ pkg, test string }
+	output := make(map[key]*strings.Builder)
+	hasSubtests := make(map[key]bool)
+
+	var tests []junit.Test
+	err := eachJSONLine(data, func(line []byte) error {
+		var e goTestEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return err
+		}
+		if e.Test == "" {
+			return nil
+		}
+
+		for parent := e.Test; strings.Contains(parent, "/"); {
+			parent = parent[:strings.LastIndex(parent, "/")]
+			hasSubtests[key{e.Package, parent}] = true
+		}
+
+		k := key{e.Package, e.Test}
+		switch e.Action {
+		case "output":
+			if output[k] == nil {
+				output[k] = &strings.Builder{}
+			}
+			output[k].WriteString(e.Output)
+		case "pass", "fail", "skip":
+			test := junit.Test{
+				Name:      e.Test,
+				Classname: e.Package,
+				Duration:  secondsToDuration(e.Elapsed),
+			}
+			if output[k] != nil {
+				test.SystemOut = output[k].String()
+			}
+
+			switch e.Action {
+			case "pass":
+				test.Status = junit.StatusPassed
+			case "skip":
+				test.Status = junit.StatusSkipped
+			case "fail":
+				test.Status = junit.StatusFailed
+				test.Error = junit.Error{Message: "test failed", Body: test.SystemOut}
+			}
+			tests = append(tests, test)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var b suiteBuilder
+	for _, test := range tests {
+		if hasSubtests[key{test.Classname, test.Name}] {
+			continue
+		}
+
+		suite, _, _ := strings.Cut(test.Name, "/")
+		b.add(suite, test)
+	}
+
+	return b.build(), nil
+}
+
+// libtestEvent is a line of libtest JSON output, as written by cargo test -- -Z unstable-options --format json and
+// cargo nextest run --message-format libtest-json.
+type libtestEvent struct {
+	Type     string  `json:"type"`
+	Event    string  `json:"event"`
+	Name     string  `json:"name"`
+	ExecTime float64 `json:"exec_time"`
+	Stdout   string  `json:"stdout"`
+	Message  string  `json:"message"`
+	Nextest  struct {
+		Crate string `json:"crate"`
+	} `json:"nextest"`
+}
+
+// parseNextestJSON reads libtest JSON output. nextest prefixes test names with the test binary and a $, which is used
+// as the suite name; otherwise the suite is named after the crate or the file.
+func parseNextestJSON(name string, data []byte) ([]junit.Suite, error) {
+	suite := strings.TrimSuffix(path.Base(name), path.Ext(name))
+
+	var b suiteBuilder
+	err := eachJSONLine(data, func(line []byte) error {
+		var e libtestEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			return err
+		}
+
+		if e.Type == "suite" {
+			if e.Nextest.Crate != "" {
+				suite = e.Nextest.Crate
+			}
+			return nil
+		}
+		if e.Type != "test" || e.Event == "started" {
+			return nil
+		}
+
+		testSuite, testName := suite, e.Name
+		if binary, rest, ok := strings.Cut(e.Name, "$"); ok {
+			testSuite, testName = binary, rest
+		}
+
+		test := junit.Test{
+			Name:      testName,
+			Duration:  secondsToDuration(e.ExecTime),
+			SystemOut: e.Stdout,
+		}
+		if i := strings.LastIndex(testName, "::"); i >= 0 {
+			test.Classname = testName[:i]
+		}
+
+		switch e.Event {
+		case "ok":
+			test.Status = junit.StatusPassed
+		case "ignored":
+			test.Status = junit.StatusSkipped
+		case "failed", "timeout":
+			test.Status = junit.StatusFailed
+			test.Error = junit.Error{Message: firstNonEmpty(e.Message, "test "+e.Event), Body: e.Stdout}
+		default:
+			return nil
+		}
+		b.add(testSuite, test)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.build(), nil
+}
+
+// ctrfReport is the subset of a Common Test Report Format document that is read, see https://ctrf.io.
+type ctrfReport struct {
+	Results struct {
+		Tool struct {
+			Name string `json:"name"`
+		} `json:"tool"`
+		Tests []struct {
+			Name     string          `json:"name"`
+			Status   string          `json:"status"`
+			Duration float64         `json:"duration"`
+			Message  string          `json:"message"`
+			Trace    string          `json:"trace"`
+			Suite    json.RawMessage `json:"suite"`
+			FilePath string          `json:"filePath"`
+			Line     int             `json:"line"`
+			Stdout   []string        `json:"stdout"`
+			Stderr   []string        `json:"stderr"`
+		} `json:"tests"`
+	} `json:"results"`
+}
+
+// parseCTRF reads a CTRF report. Tests are grouped by their suite, which may be a string or a list of nested suite
+// names, falling back to the tool name.
+func parseCTRF(data []byte) ([]junit.Suite, error) {
+	var report ctrfReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	var b suiteBuilder
+	for _, t := range report.Results.Tests {
+		suite, err := ctrfSuiteName(t.Suite)
+		if err != nil {
+			return nil, fmt.Errorf("test %q: %v", t.Name, err)
+		}
+		if suite == "" {
+			suite = report.Results.Tool.Name
+		}
+
+		test := junit.Test{
+			Name:      t.Name,
+			Classname: t.FilePath,
+			Duration:  time.Duration(t.Duration * float64(time.Millisecond)),
+			SystemOut: strings.Join(t.Stdout, "\n"),
+			SystemErr: strings.Join(t.Stderr, "\n"),
+			Properties: map[string]string{
+				"file": t.FilePath,
+			},
+		}
+		if t.Line > 0 {
+			test.Properties["line"] = strconv.Itoa(t.Line)
+		}
+
+		switch t.Status {
+		case "passed":
+			test.Status = junit.StatusPassed
+		case "failed":
+			test.Status = junit.StatusFailed
+			test.Error = junit.Error{Message: t.Message, Body: t.Trace}
+		case "skipped", "pending":
+			test.Status = junit.StatusSkipped
+		default:
+			test.Status = junit.Status(t.Status)
+		}
+		b.add(suite, test)
+	}
+
+	return b.build(), nil
+}
+
+func ctrfSuiteName(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return name, nil
+	}
+
+	var names []string
+	if err := json.Unmarshal(raw, &names); err != nil {
+		return "", fmt.Errorf("suite must be a string or a list of strings")
+	}
+
+	return strings.Join(names, " > "), nil
+}
+
+var (
+	tapTestPoint = regexp.MustCompile(`^(\s*)(not )?ok\b\s*(\d+)?\s*(?:- )?([^#]*?)\s*(?:#\s*(\S+)\s*(.*))?$`)
+	tapSubtest   = regexp.MustCompile(`^(\s*)# Subtest:\s*(.*?)\s*$`)
+	tapDuration  = regexp.MustCompile(`^\s*duration_ms:\s*([0-9.]+)`)
+)
+
+// parseTAP reads TAP output. Tests inside a "# Subtest:" belong to a suite named after the innermost enclosing
+// subtest, and other tests to a suite named after the file. The test point that closes a subtest with children is
+// the summary of that suite rather than a test of its own, so it is skipped.
+func parseTAP(name string, data []byte) ([]junit.Suite, error) {
+	type subtest struct {
+		name     string
+		indent   int
+		children int
+	}
+
+	fileSuite := strings.TrimSuffix(path.Base(name), path.Ext(name))
+
+	var b suiteBuilder
+	var stack []subtest
+	var last *junit.Test
+	var lastSuite string
+	var yaml *strings.Builder
+
+	flush := func() {
+		if last == nil {
+			return
+		}
+		if yaml != nil {
+			body := yaml.String()
+			if m := tapDuration.FindStringSubmatch(body); m != nil {
+				ms, _ := strconv.ParseFloat(m[1], 64)
+				last.Duration = time.Duration(ms * float64(time.Millisecond))
+			}
+			if last.Status == junit.StatusFailed {
+				last.Error = junit.Error{Message: last.Message, Body: body}
+			}
+		}
+		b.add(lastSuite, *last)
+		last, yaml = nil, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if yaml != nil {
+			if strings.TrimSpace(line) == "..." {
+				flush()
+			} else {
+				yaml.WriteString(strings.TrimSpace(line) + "\n")
+			}
+			continue
+		}
+
+		if strings.TrimSpace(line) == "---" && last != nil {
+			yaml = &strings.Builder{}
+			continue
+		}
+
+		if m := tapSubtest.FindStringSubmatch(line); m != nil {
+			flush()
+			stack = append(stack, subtest{name: m[2], indent: len(m[1])})
+			continue
+		}
+
+		m := tapTestPoint.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		flush()
+
+		indent, failed, description := len(m[1]), m[2] != "", m[4]
+		directive, reason := strings.ToUpper(m[5]), m[6]
+
+		// drop subtests that were never closed by a test point, such as when a runner crashed
+		for len(stack) > 0 && stack[len(stack)-1].indent > indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 && stack[len(stack)-1].name == description {
+			closed := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if closed.children > 0 {
+				continue
+			}
+		}
+
+		suite := fileSuite
+		if len(stack) > 0 {
+			suite = stack[len(stack)-1].name
+			stack[len(stack)-1].children++
+		}
+
+		test := junit.Test{Name: description, Classname: suite}
+		switch {
+		case strings.HasPrefix(directive, "SKIP"), strings.HasPrefix(directive, "TODO"):
+			test.Status = junit.StatusSkipped
+			test.Message = reason
+		case failed:
+			test.Status = junit.StatusFailed
+			test.Message = "not ok"
+			test.Error = junit.Error{Message: "not ok"}
+		default:
+			test.Status = junit.StatusPassed
+		}
+
+		last, lastSuite = &test, suite
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return b.build(), nil
+}
+
+// eachJSONLine calls fn for every non-empty line of data, adding the line number to any error.
+func eachJSONLine(data []byte, fn func(line []byte) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// sortedFormats lists the explicit formats, for error messages and docs.
+func sortedFormats() []string {
+	formats := make([]string, 0, len(resultFormatExtensions))
+	for f := range resultFormatExtensions {
+		formats = append(formats, string(f))
+	}
+	sort.Strings(formats)
+
+	return formats
+}
@@ -0,0 +1,183 @@
+package reports
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// useTestVectorSuite registers a tbdex-style vector suite with the Protocol feature's parse_rfq and parse_close
+// vectors, for the duration of the test.
+func useTestVectorSuite(t *testing.T) {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, name := range []string{"parse-rfq", "parse-close"} {
+		writeTestFile(t, root, "protocol/vectors/"+name+".json", `{"description": "`+name+`", "input": "", "output": {}, "error": false}`)
+	}
+
+	saved := VectorSuites
+	VectorSuites = []VectorSuite{{
+		Name:      "tbdex",
+		Title:     "Tbdex",
+		Root:      root,
+		Layout:    LayoutFeatureVectorsDir,
+		TestSuite: "TbdexTestVector",
+	}}
+	t.Cleanup(func() { VectorSuites = saved })
+}
+
+func writeTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResultFormats(t *testing.T) {
+	suiteMapper := func(featureRegex, vectorRegex string) Mapper {
+		return FeatureFromSuite{FeatureRegex: regexp.MustCompile(featureRegex), VectorRegex: regexp.MustCompile(vectorRegex)}
+	}
+
+	goTestJSON := `{"Action":"run","Package":"github.com/TBD54566975/tbdex-go/tbdex","Test":"TestTbdexTestVectorsProtocol"}
+{"Action":"run","Package":"github.com/TBD54566975/tbdex-go/tbdex","Test":"TestTbdexTestVectorsProtocol/parse_rfq"}
+{"Action":"pass","Package":"github.com/TBD54566975/tbdex-go/tbdex","Test":"TestTbdexTestVectorsProtocol/parse_rfq","Elapsed":0.01}
+{"Action":"run","Package":"github.com/TBD54566975/tbdex-go/tbdex","Test":"TestTbdexTestVectorsProtocol/parse_close"}
+{"Action":"output","Package":"github.com/TBD54566975/tbdex-go/tbdex","Test":"TestTbdexTestVectorsProtocol/parse_close","Output":"close_test.go:12: boom\n"}
+{"Action":"fail","Package":"github.com/TBD54566975/tbdex-go/tbdex","Test":"TestTbdexTestVectorsProtocol/parse_close","Elapsed":0.02}
+{"Action":"fail","Package":"github.com/TBD54566975/tbdex-go/tbdex","Test":"TestTbdexTestVectorsProtocol","Elapsed":0.03}
+{"Action":"pass","Package":"github.com/TBD54566975/tbdex-go/tbdex","Test":"TestOffering","Elapsed":0.01}
+{"Action":"fail","Package":"github.com/TBD54566975/tbdex-go/tbdex","Elapsed":0.05}
+`
+
+	tests := []struct {
+		name   string
+		file   string
+		format ResultFormat
+		mapper Mapper
+		data   string
+	}{
+		{
+			name:   "junit",
+			file:   "TEST-tbdex.xml",
+			format: FormatAuto,
+			mapper: suiteMapper(`TbdexTestVectors(\w+)`, `(\w+)`),
+			data: `<testsuites>
+  <testsuite name="TbdexTestVectorsProtocol" tests="2">
+    <testcase name="parse_rfq" time="0.01"/>
+    <testcase name="parse_close" time="0.02"><failure message="boom">stack</failure></testcase>
+  </testsuite>
+  <testsuite name="OfferingTest" tests="1">
+    <testcase name="parse_offering"/>
+  </testsuite>
+</testsuites>`,
+		},
+		{
+			name:   "go-test-json",
+			file:   "results.json",
+			format: FormatGoTestJSON,
+			mapper: suiteMapper(`TbdexTestVectors(\w+)`, `/(\w+)$`),
+			data:   goTestJSON,
+		},
+		{
+			name:   "go-test-json detected",
+			file:   "results.jsonl",
+			format: FormatAuto,
+			mapper: suiteMapper(`TbdexTestVectors(\w+)`, `/(\w+)$`),
+			data:   goTestJSON,
+		},
+		{
+			name:   "tap",
+			file:   "results.tap",
+			format: FormatAuto,
+			mapper: suiteMapper(`TbdexTestVectors(\w+)`, `(\w+)`),
+			data: `TAP version 13
+# Subtest: TbdexTestVectorsProtocol
+    ok 1 - parse_rfq
+      ---
+      duration_ms: 10
+      ...
+    not ok 2 - parse_close
+      ---
+      error: boom
+      ...
+    1..2
+not ok 1 - TbdexTestVectorsProtocol
+# Subtest: OfferingTest
+    ok 1 - parse_offering
+    1..1
+ok 2 - OfferingTest
+1..2
+`,
+		},
+		{
+			name:   "nextest-json",
+			file:   "nextest.json",
+			format: FormatNextestJSON,
+			mapper: FeatureFromTestName{
+				FeatureRegex: regexp.MustCompile(`::(\w+)::\w+$`),
+				VectorRegex:  regexp.MustCompile(`::(\w+)$`),
+				CamelCase:    true,
+			},
+			data: `{"type":"suite","event":"started","test_count":3,"nextest":{"crate":"tbdex"}}
+{"type":"test","event":"started","name":"tbdex$test_vectors::tbdex_test_vectors::protocol::parse_rfq"}
+{"type":"test","event":"ok","name":"tbdex$test_vectors::tbdex_test_vectors::protocol::parse_rfq","exec_time":0.01}
+{"type":"test","event":"started","name":"tbdex$test_vectors::tbdex_test_vectors::protocol::parse_close"}
+{"type":"test","event":"failed","name":"tbdex$test_vectors::tbdex_test_vectors::protocol::parse_close","exec_time":0.02,"stdout":"boom"}
+{"type":"test","event":"ok","name":"tbdex$offering::tests::parse_offering","exec_time":0.01}
+{"type":"suite","event":"failed","passed":2,"failed":1}
+`,
+		},
+		{
+			name:   "ctrf",
+			file:   "ctrf-report.json",
+			format: FormatAuto,
+			mapper: suiteMapper(`TbdexTestVectors > (\w+)`, `(\w+)`),
+			data: `{"results": {"tool": {"name": "vitest"}, "tests": [
+  {"name": "parse_rfq", "status": "passed", "duration": 10, "suite": ["TbdexTestVectors", "Protocol"]},
+  {"name": "parse_close", "status": "failed", "duration": 20, "message": "boom", "suite": ["TbdexTestVectors", "Protocol"]},
+  {"name": "parse_offering", "status": "passed", "duration": 5, "suite": "OfferingTest"}
+]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestVectorSuite(t)
+
+			dir := t.TempDir()
+			writeTestFile(t, dir, tt.file, tt.data)
+
+			sdk := NewSDKMeta("tbdex-test", "TBD54566975/tbdex-test", "test-results", "", "tbdex", nil, nil)
+			sdk.Format = tt.format
+			sdk.Mapper = tt.mapper
+
+			suites, err := readResultsDir(dir, sdk)
+			if err != nil {
+				t.Fatalf("error reading results: %v", err)
+			}
+
+			report, err := sdk.reportFromArtifact(Artifact{Suites: suites})
+			if err != nil {
+				t.Fatalf("error building report: %v", err)
+			}
+
+			want := map[string]Status{"parse_rfq": StatusPassed, "parse_close": StatusFailed}
+			for vector, status := range want {
+				if got := report.Results["Protocol"][vector].Status; got != status {
+					t.Errorf("Protocol/%s: got status %q, want %q", vector, got, status)
+				}
+			}
+
+			for _, u := range report.Unmatched {
+				t.Errorf("unexpected unmatched test: suite %q test %q", u.Suite, u.Test)
+			}
+		})
+	}
+}
@@ -2,291 +2,308 @@ package reports
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"os"
-	"regexp"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/google/go-github/v57/github"
-	"github.com/joshdk/go-junit"
+	junit "github.com/joshdk/go-junit"
 	"golang.org/x/exp/slog"
 )
 
-var SDKs = []SDKMeta{
-	NewSDKMeta(
-		"web5-js",
-		"TBD54566975/web5-js",
-		"junit-results",
-		"test-vectors",
-		"web5",
-		regexp.MustCompile(`Web5TestVectors(\w+)`),
-		regexp.MustCompile(`.* Web5TestVectors\w+ (\w+)`),
-	),
-	NewSDKMeta(
-		"web5-kt",
-		"TBD54566975/web5-kt",
-		"tests-report-junit",
-		"test-vectors",
-		"web5",
-		regexp.MustCompile(`Web5TestVectors(\w+)`),
-		regexp.MustCompile(`(\w+)`),
-	),
-	NewSDKMeta(
-		"web5-swift",
-		"TBD54566975/web5-swift",
-		"test-results",
-		"test-vectors",
-		"web5",
-		regexp.MustCompile(`Web5TestVectors(\w+)`),
-		regexp.MustCompile(`test_(\w+)`),
-	),
-	NewSDKMeta(
-		"web5-rs",
-		"TBD54566975/web5-rs",
-		"rust-test-results",
-		"tbdex-test-vectors",
-		"web5",
-		regexp.MustCompile(`::(\w+)::(\w+)::(\w+)`),
-		regexp.MustCompile(`::(\w+)$`),
-	),
-	NewSDKMeta(
-		"web5-core-kt",
-		"TBD54566975/web5-rs",
-		"kotlin-test-results",
-		"test-vectors",
-		"web5",
-		regexp.MustCompile(`Web5TestVectorsTest\$Web5TestVectors(\w+)`),
-		regexp.MustCompile(`(\w+)`),
-	),
-	NewSDKMeta(
-		"tbdex-js",
-		"TBD54566975/tbdex-js",
-		"junit-results",
-		"tbdex-test-vectors",
-		"tbdex",
-		regexp.MustCompile(`TbdexTestVectors(\w+)`),
-		regexp.MustCompile(`TbdexTestVectors(\w+) (\w+)`),
-	),
-	NewSDKMeta(
-		"tbdex-kt",
-		"TBD54566975/tbdex-kt",
-		"tests-report-junit",
-		"tbdex-test-vectors",
-		"tbdex",
-		regexp.MustCompile(`tbdex\.sdk\.\w+.TbdexTestVectors(\w+)`),
-		regexp.MustCompile(`(\w+)`),
-	),
-	NewSDKMeta(
-		"tbdex-go",
-		"TBD54566975/tbdex-go",
-		"go-test-results",
-		"tbdex-test-vectors",
-		"tbdex",
-		regexp.MustCompile(`TbdexTestVectors(\w+)`),
-		regexp.MustCompile(`TestAllParsers/(\w+)`),
-	),
-	NewSDKMeta(
-		"tbdex-rs",
-		"TBD54566975/tbdex-rs",
-		"rust-test-results",
-		"tbdex-test-vectors",
-		"tbdex",
-		regexp.MustCompile(`TbdexTestVectors(\w+)Test`),
-		regexp.MustCompile(`::(\w+)$`),
-	),
-	NewSDKMeta(
-		"tbdex-core-kt",
-		"TBD54566975/tbdex-rs",
-		"kotlin-test-results",
-		"tbdex-test-vectors",
-		"tbdex",
-		regexp.MustCompile(`tbdex\.sdk\.\w+\.TbdexTestVectors(\w+)Test`),
-		regexp.MustCompile(`(\w+)`),
-	),
-}
-
-func GetAllReports() ([]Report, error) {
-	ctx := context.Background()
+// SDKs is the default SDK registry, loaded from the embedded sdks.json. Use LoadSDKs to read a different registry.
+var SDKs []SDKMeta
 
-	err := CheckSubmoduleStatus(context.Background())
+func init() {
+	var err error
+	SDKs, err = LoadSDKs("")
 	if err != nil {
-		fmt.Println("Error checking submodule status: %v", err)
+		panic(err)
 	}
+}
 
-	var reports []Report
-	for _, sdk := range SDKs {
-		slog.Info("Processing: " + sdk.Name)
-		artifact, err := downloadArtifact(ctx, sdk)
-		//artifact, err := downloadLocal(ctx, sdk)
-		if err != nil {
-			slog.Error(fmt.Sprintf("error downloading artifact from %s: %v. continuing..", sdk.Repo, err))
-			continue
-		}
+// DefaultWorkers is the number of SDKs GetAllReports fetches and parses at once if not told otherwise.
+const DefaultWorkers = 4
 
-		suites, err := readArtifactZip(artifact)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing artifact from %s: %v", sdk.Repo, err)
-		}
+// SDKError is the error for a single SDK whose report could not be built.
+type SDKError struct {
+	SDK string
+	Err error
+}
+
+func (e SDKError) Error() string {
+	return fmt.Sprintf("%s: %v", e.SDK, e.Err)
+}
 
-		var web5TestVectorSuites []junit.Suite
+func (e SDKError) Unwrap() error {
+	return e.Err
+}
+
+// FailedSDKs returns the names of the SDKs in an error returned by GetAllReports.
+func FailedSDKs(err error) []string {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
 
-		var searchString string
-		if sdk.Type == "web5" {
-			searchString = "Web5TestVector"
-		} else if sdk.Type == "tbdex" {
-			searchString = "TbdexTestVector"
+	var names []string
+	for _, err := range errs {
+		var sdkErr SDKError
+		if errors.As(err, &sdkErr) {
+			names = append(names, sdkErr.SDK)
 		}
+	}
+
+	return names
+}
 
-		for _, suite := range suites {
-			if strings.Contains(suite.Name, searchString) {
-				web5TestVectorSuites = append(web5TestVectorSuites, suite)
+// GetAllReports fetches the junit results for each SDK from source and builds a report from them, working on up to
+// workers SDKs at once. Reports are returned in the same order as sdks. SDKs that fail are returned as a joined error of
+// SDKError, so the reports are usable even when err is non-nil. An SDK whose results could not be fetched still gets a
+// placeholder report with FetchError set; other failures leave the SDK out.
+func GetAllReports(ctx context.Context, sdks []SDKMeta, source ArtifactSource, workers int) ([]Report, error) {
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+
+	results := make([]Report, len(sdks))
+	errs := make([]error, len(sdks))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(sdks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = getReport(ctx, sdks[i], source)
+				if errs[i] != nil {
+					slog.Error("error building report", "sdk", sdks[i].Name, "error", errs[i])
+				}
 			}
-		}
+		}()
+	}
 
-		if len(web5TestVectorSuites) > 0 {
-			fmt.Println("Found these Test Vector Suites:")
-			for _, suite := range web5TestVectorSuites {
-				fmt.Println("-", suite.Name)
+	for i := range sdks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var reports []Report
+	var sdkErrs []error
+	for i, sdk := range sdks {
+		if errs[i] != nil {
+			sdkErrs = append(sdkErrs, SDKError{SDK: sdk.Name, Err: errs[i]})
+			if results[i].FetchError == nil {
+				continue
 			}
-		} else {
-			fmt.Println("No Test Vector Suites found.")
 		}
+		reports = append(reports, results[i])
+	}
 
-		var report Report
-		if sdk.Name == "web5-rs" {
-			report, err = sdk.buildReportWeb5Rs(web5TestVectorSuites)
-		} else {
-			report, err = sdk.buildReport(web5TestVectorSuites)
+	return reports, errors.Join(sdkErrs...)
+}
+
+func getReport(ctx context.Context, sdk SDKMeta, source ArtifactSource) (Report, error) {
+	slog.Info("Processing: " + sdk.Name)
+	artifacts, err := fetchArtifacts(ctx, sdk, source)
+	if err != nil {
+		var fetchErr *FetchError
+		if !errors.As(err, &fetchErr) {
+			fetchErr = &FetchError{Kind: FetchErrorDownload, Err: err}
 		}
+		report, vectorErr := sdk.unavailableReport(fetchErr)
+		if vectorErr != nil {
+			return Report{}, vectorErr
+		}
+		return report, fmt.Errorf("error fetching results: %v", err)
+	}
 
-		//report.
+	report, err := sdk.reportFromArtifact(artifacts[0])
+	if err != nil {
+		return Report{}, fmt.Errorf("error processing data from %s: %v", sdk.Repo, err)
+	}
 
+	if age := report.Age(time.Now()); sdk.MaxAge > 0 && age > sdk.MaxAge {
+		slog.Warn("artifact is stale", "sdk", sdk.Name, "created", report.Provenance.CreatedAt, "max_age", sdk.MaxAge)
+		report.Stale = true
+	}
+
+	if len(artifacts) > 1 {
+		vectors, err := KnownVectors(sdk.Type)
 		if err != nil {
-			return nil, fmt.Errorf("error processing data from %s: %v", sdk.Repo, err)
+			return Report{}, fmt.Errorf("error processing older data from %s: %v", sdk.Repo, err)
 		}
 
-		reports = append(reports, report)
+		older := make([]Report, 0, len(artifacts)-1)
+		for _, artifact := range artifacts[1:] {
+			older = append(older, sdk.olderReport(artifact, vectors))
+		}
+		report.addFlakiness(older)
 	}
 
-	return reports, nil
+	return report, nil
 }
 
-func downloadArtifact(ctx context.Context, sdk SDKMeta) ([]byte, error) {
-	owner, repo, _ := strings.Cut(sdk.Repo, "/")
+// fetchArtifacts fetches the SDK's latest artifact, followed by older ones for flaky test detection if the SDK has
+// FlakyRuns set and source supports it.
+func fetchArtifacts(ctx context.Context, sdk SDKMeta, source ArtifactSource) ([]Artifact, error) {
+	if sdk.FlakyRuns > 1 {
+		recent, ok := source.(RecentArtifactSource)
+		switch {
+		case !ok:
+			slog.Warn("artifact source can't fetch older runs, skipping flaky test detection", "sdk", sdk.Name)
+		case len(sdk.Platforms.Artifacts) > 0:
+			slog.Warn("flaky test detection is not supported with an artifact per platform", "sdk", sdk.Name)
+		default:
+			return recent.FetchRecent(ctx, sdk, sdk.FlakyRuns)
+		}
+	}
+
+	artifact, err := source.Fetch(ctx, sdk)
+	if err != nil {
+		return nil, err
+	}
 
-	slog.Info("~~Downloading artifact from ", owner+"/"+repo)
+	return []Artifact{artifact}, nil
+}
 
-	slog.Info("owner:" + owner)
-	slog.Info("repo:" + repo)
+func (s SDKMeta) reportFromArtifact(artifact Artifact) (Report, error) {
+	vectorSuite, _ := FindVectorSuite(s.Type)
+	testVectorSuites := filterTestVectorSuites(artifact.Suites, vectorSuite.TestSuite)
 
-	listOptions := &github.ListOptions{PerPage: 100}
-	artifacts, respz, err := gh.Actions.ListArtifacts(ctx, owner, repo, listOptions)
-	if (err != nil) || (respz.StatusCode != http.StatusOK) {
-		slog.Error("Error listing artifacts", "owner", owner, "repo", repo, "response", respz, "error", err)
-		return nil, fmt.Errorf("error getting artifact list: %v", err)
+	if len(testVectorSuites) > 0 {
+		for _, suite := range testVectorSuites {
+			slog.Info("found test vector suite", "sdk", s.Name, "suite", suite.Name)
+		}
+	} else {
+		slog.Warn("no test vector suites found", "sdk", s.Name)
 	}
 
-	if len(artifacts.Artifacts) == 0 {
-		return nil, fmt.Errorf("~~no artifacts found, throwing error and returning")
+	report, err := s.buildReport(testVectorSuites)
+	if err != nil {
+		return Report{}, err
 	}
+	report.Provenance = artifact.Provenance
+
+	return report, nil
+}
+
+// olderReport builds the report for an older artifact, used only for flaky test detection, without the logging of
+// reportFromArtifact, which would otherwise repeat for every run.
+func (s SDKMeta) olderReport(artifact Artifact, vectors map[string]map[string]Vector) Report {
+	vectorSuite, _ := FindVectorSuite(s.Type)
+
+	report := s.buildReportAgainst(filterTestVectorSuites(artifact.Suites, vectorSuite.TestSuite), vectors)
+	report.Provenance = artifact.Provenance
 
-	var artifactURL string
-	for _, a := range artifacts.Artifacts {
-		slog.Info("checking artifact: " + *a.Name + " branch: " + a.GetWorkflowRun().GetHeadBranch())
-		if a.GetWorkflowRun().GetHeadBranch() != "main" {
+	return report
+}
+
+// filterTestVectorSuites picks out the suites that run test vectors: those whose name contains testSuite. Suites read
+// from a format that names them after the test binary or file, such as libtest JSON, can't be matched that way, so
+// just their tests whose name contains testSuite are kept instead, comparing names ignoring case and separators so
+// web5_test_vectors matches Web5TestVector.
+func filterTestVectorSuites(suites []Suite, testSuite string) []Suite {
+	want := normalizeTestName(testSuite)
+
+	var matched []Suite
+	for _, suite := range suites {
+		if !testNamedFormats[suite.Format] {
+			if strings.Contains(suite.Name, testSuite) {
+				matched = append(matched, suite)
+			}
 			continue
 		}
 
-		slog.Info("artifact found: " + *a.Name + " at: " + *a.ArchiveDownloadURL)
-		if *a.Name == sdk.ArtifactName {
-			artifactURL = *a.ArchiveDownloadURL
-			slog.Info("downloading artifact", "repo", sdk.Repo, "commit", a.GetWorkflowRun().GetHeadSHA(), "url", artifactURL)
-			break
+		if strings.Contains(normalizeTestName(suite.Name), want) {
+			matched = append(matched, suite)
+			continue
+		}
+
+		var tests []junit.Test
+		for _, test := range suite.Tests {
+			if strings.Contains(normalizeTestName(test.Name), want) {
+				tests = append(tests, test)
+			}
+		}
+		if len(tests) > 0 {
+			suite.Tests = tests
+			suite.Aggregate()
+			matched = append(matched, suite)
 		}
 	}
 
-	if artifactURL == "" {
-		return nil, fmt.Errorf("~~no matching artifact found for %s", sdk.ArtifactName)
+	return matched
+}
+
+func normalizeTestName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', ':', '.', ' ':
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+func downloadArtifact(ctx context.Context, gh *GitHub, sdk SDKMeta, artifact *github.Artifact) ([]byte, error) {
+	owner, repo, _ := strings.Cut(sdk.Repo, "/")
+
+	// the archive download endpoint redirects to short-lived blob storage, which is fetched without our github credentials
+	artifactURL, _, err := gh.client.Actions.DownloadArtifact(ctx, owner, repo, artifact.GetID(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("error getting download url for artifact %d: %v", artifact.GetID(), err)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, artifactURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artifactURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	bearer := ghToken
-	if ghToken == "" {
-		bearer, err = ghTransport.Token(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("error getting github token: %v", err)
-		}
-	}
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", bearer))
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := gh.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making http request to %s: %v", artifactURL, err)
+		return nil, fmt.Errorf("error making http request to %s: %v", artifactURL.Host, err)
 	}
 	defer resp.Body.Close()
 
-	artifact, err := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status downloading artifact: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("error reading response body: %v", err)
 	}
 
-	slog.Info("downloaded artifact", "sdk", sdk.Repo, "size", len(artifact))
+	slog.Info("downloaded artifact", "sdk", sdk.Repo, "size", len(data))
 
-	return artifact, nil
+	return data, nil
 }
 
-func CheckSubmoduleStatus(ctx context.Context) error {
-	var allWeb5SpecCommits []*github.RepositoryCommit
-	var allTbdexCommits []*github.RepositoryCommit
-
-	// Fetch commits for web5-spec
-	web5SpecOpt := &github.CommitsListOptions{
-		SHA: "main",
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-	}
-
-	for {
-		commits, resp, err := gh.Repositories.ListCommits(ctx, "TBD54566975", "web5-spec", web5SpecOpt)
-		if err != nil {
-			log.Fatalf("Error listing web5-spec commits: %v", err)
-		}
-		allWeb5SpecCommits = append(allWeb5SpecCommits, commits...)
-		if resp.NextPage == 0 {
-			break
+func CheckSubmoduleStatus(ctx context.Context, gh *GitHub, sdks []SDKMeta) error {
+	// Fetch the commits of each vector suite's spec repo
+	specCommits := make(map[string][]*github.RepositoryCommit)
+	for _, suite := range VectorSuites {
+		if suite.Repo == "" {
+			continue
 		}
-		web5SpecOpt.Page = resp.NextPage
-	}
-
-	// Fetch commits for tbdex
-	tbdexOpt := &github.CommitsListOptions{
-		SHA: "main",
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-	}
 
-	for {
-		commits, resp, err := gh.Repositories.ListCommits(ctx, "TBD54566975", "tbdex", tbdexOpt)
+		commits, err := listSpecCommits(ctx, gh, suite.Repo)
 		if err != nil {
-			log.Fatalf("Error listing tbdex commits: %v", err)
+			return err
 		}
-		allTbdexCommits = append(allTbdexCommits, commits...)
-		if resp.NextPage == 0 {
-			break
-		}
-		tbdexOpt.Page = resp.NextPage
+		specCommits[suite.Name] = commits
 	}
 
 	// Iterate using index to modify the original SDKMeta in the slice
-	for i := range SDKs {
-		sdk := &SDKs[i]
+	for i := range sdks {
+		sdk := &sdks[i]
 
 		// default values
 		sdk.SubmoduleCommit = "-"
@@ -295,19 +312,15 @@ func CheckSubmoduleStatus(ctx context.Context) error {
 		owner, repo, _ := strings.Cut(sdk.Repo, "/")
 
 		// Determine the submodule path based on the SDK type
-		var submodulePath string
-		var allCommits []*github.RepositoryCommit
-
-		if sdk.Type == "tbdex" {
-			submodulePath = "tbdex"
-			allCommits = allTbdexCommits
-		} else {
-			submodulePath = "web5-spec"
-			allCommits = allWeb5SpecCommits
+		suite, _ := FindVectorSuite(sdk.Type)
+		if suite.Submodule == "" {
+			continue
 		}
+		submodulePath := suite.Submodule
+		allCommits := specCommits[suite.Name]
 
 		// Get the current submodule commit for the SDK repo
-		submoduleFileContent, _, _, err := gh.Repositories.GetContents(ctx, owner, repo, submodulePath, nil)
+		submoduleFileContent, _, _, err := gh.client.Repositories.GetContents(ctx, owner, repo, submodulePath, nil)
 		if err != nil || submoduleFileContent == nil || submoduleFileContent.SHA == nil {
 			fmt.Printf("error getting submodule content for %s: %v.. continuing", sdk.Repo, err)
 			continue
@@ -337,22 +350,28 @@ func CheckSubmoduleStatus(ctx context.Context) error {
 	return nil
 }
 
-// Used for testing purposes
-func downloadLocal(ctx context.Context, sdk SDKMeta) ([]byte, error) {
-	//data, err := os.ReadFile("../tbdex-junit-results.zip")
-	//data, err := os.ReadFile("../tbdex-kt-tests-report-junit.zip")
-	//data, err := os.ReadFile("../junit-results.zip")
-	//data, err := os.ReadFile("../tbdex-junit-results.zip")
-	//data, err := os.ReadFile("../tests-report-junit.zip")
-	//data, err := os.ReadFile("../junit-results-js-custom.zip")
-	//data, err := os.ReadFile("../kotlin-test-results.zip")
-	//data, err := os.ReadFile("../rust-test-results.zip")
-	//data, err := os.ReadFile("../go-test-results.zip")
-	data, err := os.ReadFile("../kotlin-test-results.zip")
-	//data, err := os.ReadFile("../tbdex-rust-test-results.zip")
-	if err != nil {
-		return nil, err
+// listSpecCommits lists every commit on the main branch of a spec repo, newest first.
+func listSpecCommits(ctx context.Context, gh *GitHub, specRepo string) ([]*github.RepositoryCommit, error) {
+	owner, repo, _ := strings.Cut(specRepo, "/")
+	opt := &github.CommitsListOptions{
+		SHA: "main",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
 	}
 
-	return data, nil
+	var allCommits []*github.RepositoryCommit
+	for {
+		commits, resp, err := gh.client.Repositories.ListCommits(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s commits: %v", repo, err)
+		}
+		allCommits = append(allCommits, commits...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return allCommits, nil
 }
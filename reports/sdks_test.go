@@ -0,0 +1,100 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	junit "github.com/joshdk/go-junit"
+	"golang.org/x/exp/slog"
+)
+
+// recentSource returns the same artifacts for every SDK.
+type recentSource []Artifact
+
+func (s recentSource) Fetch(ctx context.Context, sdk SDKMeta) (Artifact, error) {
+	return s[0], nil
+}
+
+func (s recentSource) FetchRecent(ctx context.Context, sdk SDKMeta, n int) ([]Artifact, error) {
+	return s, nil
+}
+
+func TestGetReportLogsOlderRunsQuietly(t *testing.T) {
+	useTestVectorSuite(t)
+
+	suite := Suite{Suite: junit.Suite{Name: "TbdexTestVectorsProtocol", Tests: []junit.Test{
+		{Name: "parse_rfq", Status: junit.StatusPassed},
+		{Name: "parse_unknown", Status: junit.StatusPassed},
+	}}}
+	source := recentSource{{Suites: []Suite{suite}}, {Suites: []Suite{suite}}, {Suites: []Suite{suite}}}
+
+	var logs bytes.Buffer
+	saved := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(saved) })
+
+	sdk := NewSDKMeta("tbdex-test", "TBD54566975/tbdex-test", "test-results", "", "tbdex",
+		regexp.MustCompile(`TbdexTestVectors(\w+)`), regexp.MustCompile(`(\w+)`))
+	sdk.FlakyRuns = len(source)
+
+	report, err := getReport(context.Background(), sdk, source)
+	if err != nil {
+		t.Fatalf("error building report: %v", err)
+	}
+	if flakiness := report.Results["Protocol"]["parse_rfq"].Flakiness; flakiness == nil || flakiness.Runs != len(source) {
+		t.Errorf("got flakiness %+v, want %d runs", flakiness, len(source))
+	}
+
+	for _, msg := range []string{"unmatched test case", "found test vector suite"} {
+		if got := strings.Count(logs.String(), msg); got != 1 {
+			t.Errorf("logged %q %d times, want once, for the latest run only", msg, got)
+		}
+	}
+}
+
+func TestFilterTestVectorSuites(t *testing.T) {
+	suite := func(format ResultFormat, name string, tests ...string) Suite {
+		s := Suite{Suite: junit.Suite{Name: name}, Format: format}
+		for _, test := range tests {
+			s.Tests = append(s.Tests, junit.Test{Name: test, Status: junit.StatusPassed})
+		}
+		return s
+	}
+
+	suites := []Suite{
+		suite(FormatJUnit, "Web5TestVectorsDidJwk", "resolve"),
+		// junit suites are matched by name as is, as they always have been
+		suite(FormatJUnit, "web5_test_vectors_did_jwk", "resolve"),
+		suite(FormatJUnit, "CryptoTest", "Web5TestVectorsCrypto sign"),
+		suite(FormatGoTestJSON, "TestWeb5TestVectorsDidDht", "resolve"),
+		suite(FormatCTRF, "Web5TestVectorsPresentationExchange", "select_credentials"),
+		// libtest suites are named after the test binary, so their tests are matched by name instead
+		suite(FormatNextestJSON, "web5", "dids::web5_test_vectors::did_jwk::resolve", "dids::tests::resolve"),
+		suite(FormatTAP, "results", "Web5 Test Vectors did_web resolve"),
+		suite(FormatTAP, "web5-test-vectors", "resolve"),
+	}
+
+	var got []string
+	for _, s := range filterTestVectorSuites(suites, "Web5TestVector") {
+		var tests []string
+		for _, test := range s.Tests {
+			tests = append(tests, test.Name)
+		}
+		got = append(got, s.Name+": "+strings.Join(tests, ", "))
+	}
+
+	want := []string{
+		"Web5TestVectorsDidJwk: resolve",
+		"TestWeb5TestVectorsDidDht: resolve",
+		"Web5TestVectorsPresentationExchange: select_credentials",
+		"web5: dids::web5_test_vectors::did_jwk::resolve",
+		"results: Web5 Test Vectors did_web resolve",
+		"web5-test-vectors: resolve",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got suites\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
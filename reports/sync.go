@@ -32,7 +32,7 @@ var gitCredentialStoreFile string
 
 var gitConfig = make(map[string]string)
 
-func SyncSDK(sdk SDKMeta) error {
+func SyncSDK(gh *GitHub, sdk SDKMeta) error {
 	slog.Info("syncing vectors", "repo", sdk.Repo)
 
 	tmpdir, err := os.MkdirTemp("", "vector-update")
@@ -45,7 +45,7 @@ func SyncSDK(sdk SDKMeta) error {
 	// check if a vector update branch already exists.
 	// If vector update branch exists, check it out + rebase on default branch
 	// if vector update branch does not exist, make it
-	err = clone(fmt.Sprintf("https://github.com/%s", sdk.Repo), tmpdir)
+	err = clone(gh.cloneURL(sdk.Repo), tmpdir)
 	if err != nil {
 		return fmt.Errorf("error cloning repo %s: %v", sdk.Repo, err)
 	}
@@ -81,7 +81,7 @@ func SyncSDK(sdk SDKMeta) error {
 	}
 
 	// open a pull request if one isn't already open
-	if err := openPRIfNeeded(sdk.Repo); err != nil {
+	if err := openPRIfNeeded(gh, sdk.Repo); err != nil {
 		return fmt.Errorf("error opening PR: %v", err)
 	}
 	return nil
@@ -191,26 +191,23 @@ func copyDir(src, dest string) error {
 	})
 }
 
-func ConfigureGitAuth() error {
+func ConfigureGitAuth(gh *GitHub) error {
 	slog.Info("telling git about our github token")
 
-	f, err := os.CreateTemp("", "git-credentials")
+	username, authToken, email, err := gh.gitCredentials(context.Background())
 	if err != nil {
 		return err
 	}
-	gitCredentialStoreFile = f.Name()
 
-	if ghTransport == nil {
-		panic("syncing vecotrs with a PAT not supported. See reports/README.md for instructions to create a GitHub app.")
-	}
-	authToken, err := ghTransport.Token(context.Background())
+	f, err := os.CreateTemp("", "git-credentials")
 	if err != nil {
-		slog.Error("error getting github auth token")
 		return err
 	}
+	gitCredentialStoreFile = f.Name()
+	f.Close()
 
 	cmd := exec.Command("git", "credential-store", "--file", gitCredentialStoreFile, "store")
-	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=https\nhost=github.com\nusername=%s\npassword=%s", fmt.Sprintf("%s[bot]", ghAppName), authToken))
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\nusername=%s\npassword=%s", gh.serverURL.Scheme, gh.serverURL.Host, username, authToken))
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
@@ -218,19 +215,30 @@ func ConfigureGitAuth() error {
 	}
 
 	gitConfig[gitConfigCredentialHelper] = fmt.Sprintf("store --file %s", gitCredentialStoreFile)
+	gitConfig["user.email"] = email
+	gitConfig["user.name"] = username
 
 	return nil
 }
 
 func CleanupGitAuth() error {
-	return os.Remove(gitCredentialStoreFile)
+	if gitCredentialStoreFile == "" {
+		return nil
+	}
+
+	err := os.Remove(gitCredentialStoreFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
 }
 
-func openPRIfNeeded(repo string) error {
+func openPRIfNeeded(gh *GitHub, repo string) error {
 	ctx := context.Background()
 	owner, repo, _ := strings.Cut(repo, "/")
 	head := fmt.Sprintf("%s:%s", owner, vectorUpdateBranch)
-	existing, _, err := gh.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{
+	existing, _, err := gh.client.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{
 		State: "open",
 		Head:  head,
 	})
@@ -244,7 +252,7 @@ func openPRIfNeeded(repo string) error {
 		return nil
 	}
 
-	pr, _, err := gh.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+	pr, _, err := gh.client.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
 		Title: &vectorUpdatePRTitle,
 		Body:  &vectorUpdatePRBody,
 		Head:  &head,
@@ -0,0 +1,307 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var (
+	// snakeCase is the naming convention for features and web5 vectors. parseVectorPath and parseTbdexVectorPath only
+	// split features on underscores, so anything else ends up in the feature name as is.
+	snakeCase = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+	// kebabOrSnakeCase is the naming convention for tbdex vectors, whose dashes become underscores.
+	kebabOrSnakeCase = regexp.MustCompile(`^[a-z0-9]+([-_][a-z0-9]+)*$`)
+)
+
+// schemaBaseURL is where a suite's schemas are placed for the schema compiler, so that relative $refs between them
+// resolve to other files of the suite.
+const schemaBaseURL = "file:///"
+
+// VectorLintResult lists the problems with one file of a vector suite.
+type VectorLintResult struct {
+	Suite string
+
+	// Path is the file's path relative to the suite's root.
+	Path     string
+	Problems []string
+}
+
+// LintVectors checks every vector of suite: that it is valid against the *.schema.json files in its directory, or the
+// nearest parent directory that has any, that it has a description, that its path follows the naming conventions of
+// the suite's layout, and that no two files map to the same feature and vector. Only files with problems are
+// returned, sorted by path. An error is returned if the suite can't be read at all.
+func LintVectors(suite VectorSuite) ([]VectorLintResult, error) {
+	fsys, dir, err := suite.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var vectorPaths []string
+	schemasByDir := make(map[string][]string)
+	err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return newVectorError(suite, VectorErrorUnreadable, p, "%v", err)
+		case d.IsDir():
+		case strings.HasSuffix(p, ".schema.json"):
+			schemasByDir[path.Dir(p)] = append(schemasByDir[path.Dir(p)], p)
+		case isVectorFile(p):
+			vectorPaths = append(vectorPaths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vectorPaths) == 0 {
+		return nil, newVectorError(suite, VectorErrorNoVectors, "", "no vectors found in %s", dir)
+	}
+
+	for dir, schemaPaths := range schemasByDir {
+		schemasByDir[dir] = topLevelSchemas(fsys, schemaPaths)
+	}
+
+	problems := make(map[string][]string)
+	schemas := newSchemaSet(fsys)
+	seen := make(map[string]string)
+	for _, p := range vectorPaths {
+		problems[p] = append(problems[p], lintVectorName(suite.Layout, p)...)
+
+		if feature, vector, err := suite.Layout.parsePath(p); err == nil {
+			key := feature + "/" + vector
+			if first, ok := seen[key]; ok {
+				problems[p] = append(problems[p], fmt.Sprintf("feature %s vector %s is also defined by %s", feature, vector, first))
+			} else {
+				seen[key] = p
+			}
+		}
+
+		v, err := readVectorFile(suite, fsys, p)
+		if err != nil {
+			problem := err.Error()
+			var vectorErr *VectorError
+			if errors.As(err, &vectorErr) {
+				problem = vectorErr.Err.Error()
+			}
+			problems[p] = append(problems[p], problem)
+		} else if strings.TrimSpace(v.Description) == "" {
+			problems[p] = append(problems[p], "missing description")
+		}
+
+		for _, schemaPath := range nearestSchemas(schemasByDir, path.Dir(p)) {
+			schema, err := schemas.compile(schemaPath)
+			if err != nil {
+				// reported once, against the schema itself
+				if problem := fmt.Sprintf("invalid schema: %v", err); !slices.Contains(problems[schemaPath], problem) {
+					problems[schemaPath] = append(problems[schemaPath], problem)
+				}
+				continue
+			}
+			problems[p] = append(problems[p], validateVector(fsys, schema, schemaPath, p)...)
+		}
+	}
+
+	var results []VectorLintResult
+	for p, fileProblems := range problems {
+		if len(fileProblems) > 0 {
+			results = append(results, VectorLintResult{Suite: suite.Name, Path: p, Problems: fileProblems})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+
+	return results, nil
+}
+
+// lintVectorName checks that the directories and file name of the vector at p follow the naming conventions of
+// layout.
+func lintVectorName(layout VectorLayout, p string) []string {
+	if _, _, err := layout.parsePath(p); err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	parts := strings.Split(p, "/")
+	if feature := parts[0]; !snakeCase.MatchString(feature) {
+		problems = append(problems, fmt.Sprintf("feature directory %q should be lowercase snake_case", feature))
+	}
+
+	vector := strings.TrimSuffix(parts[len(parts)-1], ".json")
+	switch {
+	case layout == LayoutFeatureVectorsDir && !kebabOrSnakeCase.MatchString(vector):
+		problems = append(problems, fmt.Sprintf("vector file name %q should be lowercase kebab-case or snake_case", vector))
+	case layout == LayoutFeatureDir && !snakeCase.MatchString(vector):
+		problems = append(problems, fmt.Sprintf("vector file name %q should be lowercase snake_case", vector))
+	}
+
+	return problems
+}
+
+// nearestSchemas returns the schemas in dir or, if there are none, in its nearest parent that has some.
+func nearestSchemas(schemasByDir map[string][]string, dir string) []string {
+	for {
+		if schemas, ok := schemasByDir[dir]; ok {
+			return schemas
+		}
+		if dir == "." {
+			return nil
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// topLevelSchemas leaves out the schemas that are referenced by another of schemaPaths, all in the same directory, as
+// they describe part of a vector rather than a whole one.
+func topLevelSchemas(fsys fs.FS, schemaPaths []string) []string {
+	referenced := make(map[string]bool)
+	for _, p := range schemaPaths {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			continue
+		}
+
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			continue
+		}
+
+		for _, ref := range schemaRefs(doc) {
+			ref, _, _ = strings.Cut(ref, "#")
+			if ref != "" && !strings.Contains(ref, "://") {
+				if target := path.Join(path.Dir(p), ref); target != p {
+					referenced[target] = true
+				}
+			}
+		}
+	}
+
+	var topLevel []string
+	for _, p := range schemaPaths {
+		if !referenced[p] {
+			topLevel = append(topLevel, p)
+		}
+	}
+
+	return topLevel
+}
+
+// schemaRefs returns the value of every $ref in a schema document.
+func schemaRefs(doc any) []string {
+	var refs []string
+	switch v := doc.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				refs = append(refs, ref)
+				continue
+			}
+			refs = append(refs, schemaRefs(value)...)
+		}
+	case []any:
+		for _, value := range v {
+			refs = append(refs, schemaRefs(value)...)
+		}
+	}
+
+	return refs
+}
+
+// schemaSet compiles the schemas of a suite on demand, so schemas that $ref each other are only compiled once.
+type schemaSet struct {
+	compiler *jsonschema.Compiler
+	compiled map[string]*jsonschema.Schema
+	errs     map[string]error
+}
+
+func newSchemaSet(fsys fs.FS) *schemaSet {
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		if p, ok := strings.CutPrefix(url, schemaBaseURL); ok {
+			return fsys.Open(p)
+		}
+		return jsonschema.LoadURL(url)
+	}
+
+	return &schemaSet{
+		compiler: compiler,
+		compiled: make(map[string]*jsonschema.Schema),
+		errs:     make(map[string]error),
+	}
+}
+
+func (s *schemaSet) compile(p string) (*jsonschema.Schema, error) {
+	if schema, ok := s.compiled[p]; ok {
+		return schema, nil
+	}
+	if err := s.errs[p]; err != nil {
+		return nil, err
+	}
+
+	schema, err := s.compiler.Compile(schemaBaseURL + p)
+	if err != nil {
+		s.errs[p] = err
+		return nil, err
+	}
+	s.compiled[p] = schema
+
+	return schema, nil
+}
+
+// validateVector validates the vector at p against schema, returning a problem for each failed check.
+func validateVector(fsys fs.FS, schema *jsonschema.Schema, schemaPath, p string) []string {
+	data, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		// already reported by readVectorFile
+		return nil
+	}
+
+	err = schema.Validate(v)
+	if err == nil {
+		return nil
+	}
+
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []string{fmt.Sprintf("%s: %v", schemaPath, err)}
+	}
+
+	// only the innermost causes, the rest just say which part of the schema they are under
+	var problems []string
+	var leaves func(*jsonschema.ValidationError)
+	leaves = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := e.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			problems = append(problems, fmt.Sprintf("%s: %s: %s", schemaPath, location, e.Message))
+		}
+		for _, cause := range e.Causes {
+			leaves(cause)
+		}
+	}
+	leaves(validationErr)
+
+	return problems
+}
@@ -0,0 +1,212 @@
+package reports
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLintVectors(t *testing.T) {
+	const (
+		web5Vector = `{"description": "resolve", "vectors": [{"description": "resolve", "input": "did:jwk:abc", "output": {}}]}`
+
+		// vector.schema.json refers to case.schema.json, which is only checked against each case, not the whole file
+		vectorSchema = `{"type": "object", "required": ["description", "vectors"], "properties": {"vectors": {"type": "array", "items": {"$ref": "case.schema.json"}}}}`
+		caseSchema   = `{"type": "object", "required": ["input"]}`
+	)
+
+	tests := []struct {
+		name   string
+		layout VectorLayout
+		files  map[string]string
+
+		// want holds, by path, a substring of each problem expected for that file, in order
+		want map[string][]string
+	}{
+		{
+			name:   "clean",
+			layout: LayoutFeatureDir,
+			files: map[string]string{
+				"vectors.schema.json":        `{"type": "object", "required": ["description"]}`,
+				"did_jwk/vector.schema.json": vectorSchema,
+				"did_jwk/case.schema.json":   caseSchema,
+				"did_jwk/resolve.json":       web5Vector,
+				"crypto_es256k/sign.json":    `{"description": "sign", "vectors": []}`,
+				"package.json":               `{}`,
+			},
+		},
+		{
+			name:   "schema validation",
+			layout: LayoutFeatureDir,
+			files: map[string]string{
+				"did_jwk/vector.schema.json": vectorSchema,
+				"did_jwk/case.schema.json":   caseSchema,
+				"did_jwk/resolve.json":       web5Vector,
+				"did_jwk/resolve_key.json":   `{"description": "resolve", "vectors": [{"description": "no input"}]}`,
+			},
+			want: map[string][]string{
+				"did_jwk/resolve_key.json": {"did_jwk/vector.schema.json: /vectors/0: missing properties: 'input'"},
+			},
+		},
+		{
+			name:   "nearest parent schemas",
+			layout: LayoutFeatureDir,
+			files: map[string]string{
+				"vectors.schema.json":        `{"type": "object", "required": ["spec"]}`,
+				"did_jwk/vector.schema.json": vectorSchema,
+				"did_jwk/case.schema.json":   caseSchema,
+				"did_jwk/resolve.json":       web5Vector,
+				"crypto_es256k/sign.json":    `{"description": "sign", "vectors": []}`,
+			},
+			want: map[string][]string{
+				"crypto_es256k/sign.json": {"vectors.schema.json: /: missing properties: 'spec'"},
+			},
+		},
+		{
+			name:   "naming",
+			layout: LayoutFeatureDir,
+			files: map[string]string{
+				"DidJwk/resolve.json":        web5Vector,
+				"did_jwk/Resolve-Key.json":   web5Vector,
+				"did_jwk/extra/resolve.json": web5Vector,
+			},
+			want: map[string][]string{
+				"DidJwk/resolve.json":        {`feature directory "DidJwk" should be lowercase snake_case`},
+				"did_jwk/Resolve-Key.json":   {`vector file name "Resolve-Key" should be lowercase snake_case`},
+				"did_jwk/extra/resolve.json": {"expected <feature>/<vector>.json"},
+			},
+		},
+		{
+			name:   "tbdex naming",
+			layout: LayoutFeatureVectorsDir,
+			files: map[string]string{
+				"protocol/vectors/parse-rfq.json":   `{"description": "parse rfq"}`,
+				"protocol/vectors/Parse-Quote.json": `{"description": "parse quote"}`,
+				"protocol/parse-close.json":         `{"description": "parse close"}`,
+			},
+			want: map[string][]string{
+				"protocol/vectors/Parse-Quote.json": {`vector file name "Parse-Quote" should be lowercase kebab-case or snake_case`},
+				"protocol/parse-close.json":         {"expected <feature>/vectors/<vector>.json"},
+			},
+		},
+		{
+			name:   "duplicates after camel-casing",
+			layout: LayoutFeatureVectorsDir,
+			files: map[string]string{
+				"protocol/vectors/parse-rfq.json": `{"description": "parse rfq"}`,
+				"protocol/vectors/parse_rfq.json": `{"description": "parse rfq again"}`,
+			},
+			want: map[string][]string{
+				"protocol/vectors/parse_rfq.json": {"feature Protocol vector parse_rfq is also defined by protocol/vectors/parse-rfq.json"},
+			},
+		},
+		{
+			name:   "unreadable files",
+			layout: LayoutFeatureDir,
+			files: map[string]string{
+				"did_jwk/vector.schema.json": `{"type": "not-a-type"}`,
+				"did_jwk/resolve.json":       web5Vector,
+				"did_jwk/resolve_key.json":   `{"description": `,
+				"did_jwk/sign.json":          `{"vectors": []}`,
+			},
+			want: map[string][]string{
+				// reported once, however many vectors use it
+				"did_jwk/vector.schema.json": {"invalid schema"},
+				"did_jwk/resolve_key.json":   {"unexpected end of JSON input"},
+				"did_jwk/sign.json":          {"missing description"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				writeTestFile(t, root, name, content)
+			}
+
+			suite := VectorSuite{Name: "test", Root: root, Layout: tt.layout}
+			results, err := LintVectors(suite)
+			if err != nil {
+				t.Fatalf("error linting vectors: %v", err)
+			}
+
+			if tt.want == nil {
+				if results != nil {
+					t.Fatalf("got %+v, want no problems", results)
+				}
+				return
+			}
+
+			var paths []string
+			for _, result := range results {
+				paths = append(paths, result.Path)
+
+				want := tt.want[result.Path]
+				if len(result.Problems) != len(want) {
+					t.Errorf("%s: got problems %q, want %d", result.Path, result.Problems, len(want))
+					continue
+				}
+				for i, problem := range result.Problems {
+					if !strings.Contains(problem, want[i]) {
+						t.Errorf("%s: got problem %q, want %q", result.Path, problem, want[i])
+					}
+				}
+			}
+			if !slices.IsSorted(paths) {
+				t.Errorf("got results for %v, want them sorted by path", paths)
+			}
+			if len(paths) != len(tt.want) {
+				t.Errorf("got problems for %v, want problems for %d files", paths, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestLintVectorsNoVectors(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "vectors.schema.json", `{}`)
+
+	_, err := LintVectors(VectorSuite{Name: "test", Root: root, Layout: LayoutFeatureDir})
+	var vectorErr *VectorError
+	if !errors.As(err, &vectorErr) || vectorErr.Kind != VectorErrorNoVectors {
+		t.Errorf("got error %v, want %s", err, VectorErrorNoVectors)
+	}
+}
+
+func TestTopLevelAndNearestSchemas(t *testing.T) {
+	fsys := fstest.MapFS{
+		"vectors.schema.json":        {Data: []byte(`{"type": "object"}`)},
+		"did_jwk/vector.schema.json": {Data: []byte(`{"items": {"$ref": "case.schema.json#/definitions/case"}}`)},
+		"did_jwk/case.schema.json":   {Data: []byte(`{"$ref": "#/definitions/case", "definitions": {"case": {}}}`)},
+		"did_jwk/other.schema.json":  {Data: []byte(`{"$ref": "https://example.com/other.schema.json"}`)},
+	}
+
+	topLevel := topLevelSchemas(fsys, []string{"did_jwk/vector.schema.json", "did_jwk/case.schema.json", "did_jwk/other.schema.json"})
+	if want := []string{"did_jwk/vector.schema.json", "did_jwk/other.schema.json"}; !slices.Equal(topLevel, want) {
+		t.Errorf("got top-level schemas %v, want %v", topLevel, want)
+	}
+
+	schemasByDir := map[string][]string{
+		".":       {"vectors.schema.json"},
+		"did_jwk": topLevel,
+	}
+	tests := []struct {
+		dir  string
+		want []string
+	}{
+		{"did_jwk", topLevel},
+		{"crypto", []string{"vectors.schema.json"}},
+		{"crypto/es256k", []string{"vectors.schema.json"}},
+	}
+	for _, tt := range tests {
+		if got := nearestSchemas(schemasByDir, tt.dir); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got schemas %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+	if got := nearestSchemas(map[string][]string{"did_jwk": topLevel}, "crypto"); got != nil {
+		t.Errorf("got schemas %v for a directory with none above it", got)
+	}
+}
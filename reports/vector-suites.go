@@ -0,0 +1,483 @@
+package reports
+
+import (
+	"archive/tar"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VectorLayout is how a vector suite lays out its files, which decides the feature and name of each vector.
+type VectorLayout string
+
+const (
+	// LayoutFeatureDir is <feature>/<vector>.json, as used by web5-spec. snake_case features are converted to
+	// CamelCase.
+	LayoutFeatureDir VectorLayout = "feature-dir"
+
+	// LayoutFeatureVectorsDir is <feature>/vectors/<vector>.json, as used by tbdex. snake_case features are converted
+	// to CamelCase and dashes in vector names become underscores.
+	LayoutFeatureVectorsDir VectorLayout = "feature-vectors-dir"
+)
+
+func parseVectorLayout(s string) (VectorLayout, error) {
+	switch layout := VectorLayout(s); layout {
+	case LayoutFeatureDir, LayoutFeatureVectorsDir:
+		return layout, nil
+	default:
+		return "", fmt.Errorf("unknown layout %q, expected %s or %s", s, LayoutFeatureDir, LayoutFeatureVectorsDir)
+	}
+}
+
+// parsePath returns the feature and vector name of the vector file at path, relative to the suite's root, or an error
+// if the path doesn't fit the layout.
+func (l VectorLayout) parsePath(path string) (feature string, vector string, err error) {
+	parts := strings.Split(path, "/")
+	if l == LayoutFeatureVectorsDir {
+		if len(parts) != 3 || parts[1] != "vectors" {
+			return "", "", fmt.Errorf("expected <feature>/vectors/<vector>.json for layout %s", l)
+		}
+		feature, vector = parseTbdexVectorPath(path)
+	} else {
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf("expected <feature>/<vector>.json for layout %s", l)
+		}
+		feature, vector = parseVectorPath(path)
+	}
+
+	if feature == "" || vector == "" {
+		return "", "", fmt.Errorf("empty feature or vector name")
+	}
+
+	return feature, vector, nil
+}
+
+// VectorSuite is a family of test vectors, such as web5 or tbdex. SDKs are tested against the suite whose Name is their
+// Type.
+type VectorSuite struct {
+	Name string
+
+	// Title is the suite's name on the report page.
+	Title string
+
+	// Root is the directory holding the vectors. A relative root is looked up in the working directory and each of its
+	// parents, so the runner works from anywhere in this repo.
+	Root string
+
+	// Ref, if set, is a git commit, tag or branch to read the vectors from, instead of the files checked out at Root.
+	// Root must then be inside a git repo.
+	Ref string
+
+	Layout VectorLayout
+
+	// TestSuite is part of the name of the junit test suites in which SDKs run these vectors, such as
+	// "Web5TestVector". Other suites in an SDK's artifact are ignored.
+	TestSuite string
+
+	// Repo is the spec repo, as owner/name, that SDKs include as a submodule at Submodule. These are used to check
+	// how far behind each SDK's copy of the vectors is.
+	Repo      string
+	Submodule string
+
+	// RepoPath is the directory in Repo holding the vectors, for fetching a release of them from GitHub.
+	RepoPath string
+
+	// Version is the spec release or commit the suite is pinned to, see PinVectorSuite, or empty for whatever is
+	// checked out.
+	Version string
+}
+
+// Label is the suite's title, with the release it is pinned to, if any.
+func (s VectorSuite) Label() string {
+	if s.Version == "" {
+		return s.Title
+	}
+
+	return s.Title + " " + s.Version
+}
+
+var (
+	//go:embed vectors.json
+	defaultVectorSuiteConfig []byte
+
+	// VectorSuites is the vector suite registry, loaded from the embedded vectors.json. Set it to the result of
+	// LoadVectorSuites, before loading SDKs, to use a different registry.
+	VectorSuites = mustParseVectorSuites(defaultVectorSuiteConfig)
+)
+
+// FindVectorSuite returns the registered vector suite with the given name.
+func FindVectorSuite(name string) (VectorSuite, bool) {
+	for _, suite := range VectorSuites {
+		if suite.Name == name {
+			return suite, true
+		}
+	}
+
+	return VectorSuite{}, false
+}
+
+// vectorSuiteConfigFile is the on-disk format of the vector suite registry. See vectors.json for the default list.
+type vectorSuiteConfigFile struct {
+	Suites []vectorSuiteConfig `json:"suites"`
+}
+
+type vectorSuiteConfig struct {
+	Name      string `json:"name"`
+	Title     string `json:"title,omitempty"`
+	Root      string `json:"root"`
+	Ref       string `json:"ref,omitempty"`
+	Layout    string `json:"layout"`
+	TestSuite string `json:"testSuite"`
+	Repo      string `json:"repo,omitempty"`
+	Submodule string `json:"submodule,omitempty"`
+	RepoPath  string `json:"repoPath,omitempty"`
+}
+
+// LoadVectorSuites reads the vector suite registry from the file at path. Relative roots in it are relative to the
+// file's directory. If path is empty, the default registry embedded from vectors.json is used.
+func LoadVectorSuites(path string) ([]VectorSuite, error) {
+	if path == "" {
+		return ParseVectorSuites(bytes.NewReader(defaultVectorSuiteConfig))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening vector suite config: %v", err)
+	}
+	defer f.Close()
+
+	suites, err := ParseVectorSuites(f)
+	if err != nil {
+		return nil, fmt.Errorf("error loading vector suite config from %s: %w", path, err)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving vector suite config directory: %v", err)
+	}
+	for i := range suites {
+		if !filepath.IsAbs(suites[i].Root) {
+			suites[i].Root = filepath.Join(dir, suites[i].Root)
+		}
+	}
+
+	return suites, nil
+}
+
+// ParseVectorSuites decodes and validates a vector suite registry. Every invalid entry is reported, not just the first
+// one.
+func ParseVectorSuites(r io.Reader) ([]VectorSuite, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var config vectorSuiteConfigFile
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("error parsing vector suite config: %v", err)
+	}
+
+	if len(config.Suites) == 0 {
+		return nil, errors.New("vector suite config does not list any suites")
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	suites := make([]VectorSuite, 0, len(config.Suites))
+	for i, c := range config.Suites {
+		suite, err := c.toVectorSuite()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("suites[%d] (%s): %w", i, c.Name, err))
+			continue
+		}
+
+		if seen[suite.Name] {
+			errs = append(errs, fmt.Errorf("suites[%d] (%s): duplicate suite name", i, c.Name))
+			continue
+		}
+		seen[suite.Name] = true
+
+		suites = append(suites, suite)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return suites, nil
+}
+
+func mustParseVectorSuites(data []byte) []VectorSuite {
+	suites, err := ParseVectorSuites(bytes.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+
+	return suites
+}
+
+func (c vectorSuiteConfig) toVectorSuite() (VectorSuite, error) {
+	var errs []error
+
+	required := []struct{ field, value string }{
+		{"name", c.Name},
+		{"root", c.Root},
+		{"layout", c.Layout},
+		{"testSuite", c.TestSuite},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", r.field))
+		}
+	}
+
+	var layout VectorLayout
+	if c.Layout != "" {
+		var err error
+		if layout, err = parseVectorLayout(c.Layout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if owner, repo, ok := strings.Cut(c.Repo, "/"); c.Repo != "" && (!ok || owner == "" || repo == "" || strings.Contains(repo, "/")) {
+		errs = append(errs, fmt.Errorf("repo %q must be in the form owner/name", c.Repo))
+	}
+
+	if (c.Repo == "") != (c.Submodule == "") {
+		errs = append(errs, errors.New("repo and submodule must be set together"))
+	}
+
+	if c.RepoPath != "" && c.Repo == "" {
+		errs = append(errs, errors.New("repoPath requires repo"))
+	}
+
+	if len(errs) > 0 {
+		return VectorSuite{}, errors.Join(errs...)
+	}
+
+	title := c.Title
+	if title == "" {
+		title = c.Name
+	}
+
+	return VectorSuite{
+		Name:      c.Name,
+		Title:     title,
+		Root:      c.Root,
+		Ref:       c.Ref,
+		Layout:    layout,
+		TestSuite: c.TestSuite,
+		Repo:      c.Repo,
+		Submodule: c.Submodule,
+		RepoPath:  c.RepoPath,
+	}, nil
+}
+
+// dir finds the suite's root directory. A relative root is looked for in the working directory and then each of its
+// parents; if it isn't found, the root relative to the working directory is returned.
+func (s VectorSuite) dir() (string, error) {
+	if filepath.IsAbs(s.Root) {
+		return s.Root, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting working directory: %v", err)
+	}
+
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		candidate := filepath.Join(dir, s.Root)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, nil
+		}
+
+		if filepath.Dir(dir) == dir {
+			return filepath.Join(wd, s.Root), nil
+		}
+	}
+}
+
+// FS returns the files of the suite, from Root or, if Ref is set, from that commit of the git repo Root is in.
+func (s VectorSuite) FS() (fs.FS, error) {
+	dir, err := s.dir()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Ref == "" {
+		return os.DirFS(dir), nil
+	}
+
+	return gitRefFS(dir, s.Ref)
+}
+
+// gitRefFS reads the files under dir as of ref. dir must be inside a git work tree; git archive only includes the
+// subdirectory it is run from, with paths relative to it.
+func gitRefFS(dir, ref string) (fs.FS, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "archive", "--format=tar", ref)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error reading %s at %s: %v: %s", dir, ref, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return tarFS(&stdout)
+}
+
+// tarFS loads the regular files of a tar archive into memory.
+func tarFS(r io.Reader) (fs.FS, error) {
+	files := make(memFS)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading tar archive: %v", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s from tar archive: %v", header.Name, err)
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if !fs.ValidPath(name) {
+			continue
+		}
+		files[name] = &memFile{name: path.Base(name), data: data, modTime: header.ModTime}
+	}
+
+	return files, nil
+}
+
+// memFS is a read-only file system held in memory, with files keyed by their slash-separated path. Directories are
+// implied by the paths of the files in them.
+type memFS map[string]*memFile
+
+func (fsys memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if f, ok := fsys[name]; ok {
+		return &openMemFile{info: f, r: bytes.NewReader(f.data)}, nil
+	}
+
+	entries := fsys.readDir(name)
+	if entries == nil && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return &memDir{info: memDirInfo(path.Base(name)), entries: entries}, nil
+}
+
+// readDir lists the files and directories directly in dir, sorted by name, or nil if there are none.
+func (fsys memFS) readDir(dir string) []fs.DirEntry {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+
+	children := make(map[string]fs.DirEntry)
+	for p, f := range fsys {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok {
+			continue
+		}
+
+		if child, _, isDir := strings.Cut(rest, "/"); isDir {
+			children[child] = fs.FileInfoToDirEntry(memDirInfo(child))
+		} else {
+			children[child] = fs.FileInfoToDirEntry(f)
+		}
+	}
+
+	var entries []fs.DirEntry
+	for _, entry := range children {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries
+}
+
+// memFile is a file of a memFS, and its fs.FileInfo.
+type memFile struct {
+	name    string
+	data    []byte
+	modTime time.Time
+}
+
+func (f *memFile) Name() string       { return f.name }
+func (f *memFile) Size() int64        { return int64(len(f.data)) }
+func (f *memFile) Mode() fs.FileMode  { return 0444 }
+func (f *memFile) ModTime() time.Time { return f.modTime }
+func (f *memFile) IsDir() bool        { return false }
+func (f *memFile) Sys() any           { return nil }
+
+type openMemFile struct {
+	info *memFile
+	r    *bytes.Reader
+}
+
+func (f *openMemFile) Read(p []byte) (int, error) { return f.r.Read(p) }
+func (f *openMemFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openMemFile) Close() error               { return nil }
+
+// memDirInfo is the fs.FileInfo of a memFS directory, by name.
+type memDirInfo string
+
+func (d memDirInfo) Name() string       { return string(d) }
+func (d memDirInfo) Size() int64        { return 0 }
+func (d memDirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (d memDirInfo) ModTime() time.Time { return time.Time{} }
+func (d memDirInfo) IsDir() bool        { return true }
+func (d memDirInfo) Sys() any           { return nil }
+
+type memDir struct {
+	info    memDirInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: string(d.info), Err: errors.New("is a directory")}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return entries, nil
+	}
+
+	if len(entries) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(entries))
+	d.offset += n
+
+	return entries[:n], nil
+}
@@ -0,0 +1,40 @@
+package reports
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTarFS(t *testing.T) {
+	archive := tarGzBytes(t, []testFile{
+		{"./vectors.schema.json", `{}`},
+		{"did_jwk/resolve.json", `{"description": "resolve"}`},
+		{"did_jwk/nested/deep.json", `{}`},
+		{"crypto_es256k/sign.json", `{"description": "sign"}`},
+	})
+
+	// tarGzBytes compresses the archive, which git archive --format=tar doesn't
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys, err := tarFS(gz)
+	if err != nil {
+		t.Fatalf("error reading tar archive: %v", err)
+	}
+
+	if err := fstest.TestFS(fsys, "vectors.schema.json", "did_jwk/resolve.json", "did_jwk/nested/deep.json", "crypto_es256k/sign.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fs.ReadFile(fsys, "did_jwk/resolve.json")
+	if err != nil || string(data) != `{"description": "resolve"}` {
+		t.Errorf("got %q, %v, want the file's contents", data, err)
+	}
+	if _, err := fs.Stat(fsys, "did_jwk/missing.json"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
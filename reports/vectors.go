@@ -1,52 +1,373 @@
 package reports
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 )
 
-func getKnownVectors(vectorType string) map[string]map[string]bool {
-	if vectorType == "web5" {
-		return readKnownVectors("../test-vectors")
-	} else if vectorType == "tbdex" {
-		return readKnownVectors("../tbdex-test-vectors")
+// maxVectorSummary is how many characters of a vector's input or output are kept in its summary.
+const maxVectorSummary = 120
+
+// Vector describes what a test vector file tests.
+type Vector struct {
+	Feature string
+	Name    string
+
+	// File is the path of the vector file, relative to the root of the vectors.
+	File string
+
+	Description string
+
+	// SpecRefs are the spec sections the vector checks, from its optional "spec" field.
+	SpecRefs []string
+
+	// Cases are the inputs and expected outputs in the file. web5 vector files can have several, tbdex ones have one.
+	Cases []VectorCase
+}
+
+// VectorCase is one input and expected output of a vector.
+type VectorCase struct {
+	Description string
+
+	// ErrorCase is set if the SDK is expected to fail on the input.
+	ErrorCase bool
+
+	// Input and Output are the case's input and expected output as compact JSON, shortened to maxVectorSummary
+	// characters.
+	Input  string
+	Output string
+}
+
+// IsErrorCase reports whether every case of the vector expects the SDK to fail.
+func (v Vector) IsErrorCase() bool {
+	return len(v.Cases) > 0 && v.ErrorCases() == len(v.Cases)
+}
+
+// ErrorCases is how many of the vector's cases expect the SDK to fail.
+func (v Vector) ErrorCases() int {
+	count := 0
+	for _, c := range v.Cases {
+		if c.ErrorCase {
+			count++
+		}
 	}
-	return nil
+
+	return count
+}
+
+// Summary is a plain text description of the vector, for tooltips.
+func (v Vector) Summary() string {
+	lines := []string{}
+	if v.Description != "" {
+		lines = append(lines, v.Description)
+	}
+
+	switch {
+	case v.IsErrorCase():
+		lines = append(lines, "expects an error")
+	case v.ErrorCases() > 0:
+		lines = append(lines, fmt.Sprintf("%d of %d cases expect an error", v.ErrorCases(), len(v.Cases)))
+	}
+
+	if len(v.SpecRefs) > 0 {
+		lines = append(lines, "spec: "+strings.Join(v.SpecRefs, ", "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// VectorErrorKind classifies why a vector suite's vectors could not be read.
+type VectorErrorKind string
+
+const (
+	// VectorErrorUnknownSuite means no vector suite has the requested name.
+	VectorErrorUnknownSuite VectorErrorKind = "unknown-suite"
+
+	// VectorErrorMissingRoot means the suite's root directory, or its ref, doesn't exist. Usually a submodule that
+	// isn't checked out.
+	VectorErrorMissingRoot VectorErrorKind = "missing-root"
+
+	// VectorErrorUnreadable means a file or directory under the root could not be read.
+	VectorErrorUnreadable VectorErrorKind = "unreadable-file"
+
+	// VectorErrorInvalidJSON means a vector file is not valid JSON, or not in the vector format.
+	VectorErrorInvalidJSON VectorErrorKind = "invalid-json"
+
+	// VectorErrorBadPath means a vector file's path doesn't fit the suite's layout, so it has no feature or name.
+	VectorErrorBadPath VectorErrorKind = "unparseable-path"
+
+	// VectorErrorNoVectors means the suite's root has no vectors in it.
+	VectorErrorNoVectors VectorErrorKind = "no-vectors"
+)
+
+// VectorError is returned when a vector suite can't be read. Path is the file it is about, relative to the suite's
+// root, or empty if it is about the whole suite.
+type VectorError struct {
+	Suite string
+	Kind  VectorErrorKind
+	Path  string
+	Err   error
+}
+
+func (e *VectorError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("vector suite %s: %s: %v", e.Suite, e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("vector suite %s: %v", e.Suite, e.Err)
+}
+
+func (e *VectorError) Unwrap() error {
+	return e.Err
+}
+
+func newVectorError(suite VectorSuite, kind VectorErrorKind, path string, format string, args ...any) *VectorError {
+	return &VectorError{Suite: suite.Name, Kind: kind, Path: path, Err: fmt.Errorf(format, args...)}
+}
+
+type knownVectorsResult struct {
+	vectors map[string]map[string]Vector
+	err     error
+}
+
+var (
+	// knownVectorsCache holds the vectors of each suite once read, as reports for every SDK need them.
+	knownVectorsCache   = make(map[VectorSuite]knownVectorsResult)
+	knownVectorsCacheMu sync.Mutex
+)
+
+// KnownVectors returns every vector of the vector suite with the given name, such as an SDK's Type, by feature and
+// name. Errors are a *VectorError, or several joined together.
+func KnownVectors(suiteName string) (map[string]map[string]Vector, error) {
+	suite, ok := FindVectorSuite(suiteName)
+	if !ok {
+		return nil, &VectorError{Suite: suiteName, Kind: VectorErrorUnknownSuite, Err: errors.New("unknown vector suite")}
+	}
+
+	return suite.Vectors()
+}
+
+// Vectors returns every vector of the suite, by feature and name. Errors are a *VectorError, or several joined
+// together.
+func (s VectorSuite) Vectors() (map[string]map[string]Vector, error) {
+	knownVectorsCacheMu.Lock()
+	defer knownVectorsCacheMu.Unlock()
+
+	result, ok := knownVectorsCache[s]
+	if !ok {
+		result.vectors, result.err = readKnownVectors(s)
+		knownVectorsCache[s] = result
+	}
+
+	return result.vectors, result.err
+}
+
+// CheckVectorSuites reads every registered vector suite, returning the problems with all of them. Run it before
+// building reports, which are meaningless without the vectors.
+func CheckVectorSuites() error {
+	var errs []error
+	for _, suite := range VectorSuites {
+		if _, err := KnownVectors(suite.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
-func readKnownVectors(dir string) map[string]map[string]bool {
-	knownVectors := make(map[string]map[string]bool)
-	err := filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+// readKnownVectors reads every vector of suite. Problems with individual files are all reported, not just the first.
+func readKnownVectors(suite VectorSuite) (map[string]map[string]Vector, error) {
+	fsys, dir, err := suite.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	knownVectors := make(map[string]map[string]Vector)
+	count := 0
+	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, newVectorError(suite, VectorErrorUnreadable, path, "%v", err))
+			return nil
+		}
 
-		if strings.HasSuffix(path, "package-lock.json") || strings.HasSuffix(path, "package.json") {
+		if d.IsDir() || !isVectorFile(path) {
 			return nil
 		}
 
-		if !strings.HasSuffix(path, ".json") || strings.HasSuffix(path, ".schema.json") {
+		feature, vector, err := suite.Layout.parsePath(path)
+		if err != nil {
+			errs = append(errs, newVectorError(suite, VectorErrorBadPath, path, "%v", err))
 			return nil
 		}
 
-		var feature, vector string
-		if strings.Contains(dir, "tbdex") {
-			feature, vector = parseTbdexVectorPath(strings.TrimPrefix(path, dir))
-		} else {
-			feature, vector = parseVectorPath(strings.TrimPrefix(path, dir))
+		v, err := readVectorFile(suite, fsys, path)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
 		}
+		v.Feature = feature
+		v.Name = vector
+		v.File = path
 
 		if knownVectors[feature] == nil {
-			knownVectors[feature] = make(map[string]bool)
+			knownVectors[feature] = make(map[string]Vector)
 		}
-		knownVectors[feature][vector] = true
+		knownVectors[feature][vector] = v
+		count++
 
 		return nil
 	})
+	if err != nil {
+		errs = append(errs, newVectorError(suite, VectorErrorUnreadable, "", "%v", err))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if count == 0 {
+		return nil, newVectorError(suite, VectorErrorNoVectors, "", "no vectors found in %s", dir)
+	}
+
+	return knownVectors, nil
+}
 
+// open returns the files of suite and the directory they are read from, or a *VectorError if the root is missing.
+func (s VectorSuite) open() (fs.FS, string, error) {
+	dir, err := s.dir()
 	if err != nil {
-		panic(err)
+		return nil, "", newVectorError(s, VectorErrorMissingRoot, "", "%v", err)
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, "", newVectorError(s, VectorErrorMissingRoot, "", "root %s is not a directory, check that the vectors are checked out", dir)
+	}
+
+	fsys, err := s.FS()
+	if err != nil {
+		return nil, "", newVectorError(s, VectorErrorMissingRoot, "", "%v", err)
+	}
+
+	return fsys, dir, nil
+}
+
+// isVectorFile reports whether the file at path is a vector, rather than a schema or npm package file.
+func isVectorFile(path string) bool {
+	if strings.HasSuffix(path, "package-lock.json") || strings.HasSuffix(path, "package.json") {
+		return false
+	}
+
+	return strings.HasSuffix(path, ".json") && !strings.HasSuffix(path, ".schema.json")
+}
+
+// vectorFile is the format of a vector file. web5 files list their cases in Vectors, while tbdex files are a single
+// case, with Input, Output and Error at the top level.
+type vectorFile struct {
+	Description string          `json:"description"`
+	Spec        specRefs        `json:"spec"`
+	Vectors     []vectorCase    `json:"vectors"`
+	Input       json.RawMessage `json:"input"`
+	Output      json.RawMessage `json:"output"`
+	Error       bool            `json:"error"`
+}
+
+type vectorCase struct {
+	Description string          `json:"description"`
+	Spec        specRefs        `json:"spec"`
+	Input       json.RawMessage `json:"input"`
+	Output      json.RawMessage `json:"output"`
+	Errors      bool            `json:"errors"`
+}
+
+// specRefs is a spec reference, such as a URL to a section of the spec, or a list of them.
+type specRefs []string
+
+func (s *specRefs) UnmarshalJSON(data []byte) error {
+	var ref string
+	if err := json.Unmarshal(data, &ref); err == nil {
+		*s = specRefs{ref}
+		return nil
+	}
+
+	var refs []string
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return fmt.Errorf("spec must be a string or a list of strings")
+	}
+	*s = refs
+
+	return nil
+}
+
+// readVectorFile reads the description and cases of a vector file.
+func readVectorFile(suite VectorSuite, fsys fs.FS, path string) (Vector, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return Vector{}, newVectorError(suite, VectorErrorUnreadable, path, "%v", err)
+	}
+
+	var file vectorFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Vector{}, newVectorError(suite, VectorErrorInvalidJSON, path, "%v", err)
+	}
+
+	v := Vector{
+		Description: file.Description,
+		SpecRefs:    file.Spec,
+	}
+
+	if file.Vectors == nil {
+		v.Cases = []VectorCase{{
+			Description: file.Description,
+			ErrorCase:   file.Error,
+			Input:       summarizeJSON(file.Input),
+			Output:      summarizeJSON(file.Output),
+		}}
+		return v, nil
+	}
+
+	for _, c := range file.Vectors {
+		v.Cases = append(v.Cases, VectorCase{
+			Description: c.Description,
+			ErrorCase:   c.Errors,
+			Input:       summarizeJSON(c.Input),
+			Output:      summarizeJSON(c.Output),
+		})
+		for _, ref := range c.Spec {
+			if !slices.Contains(v.SpecRefs, ref) {
+				v.SpecRefs = append(v.SpecRefs, ref)
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// summarizeJSON compacts raw and shortens it to maxVectorSummary characters.
+func summarizeJSON(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return ""
+	}
+
+	summary := []rune(compact.String())
+	if len(summary) <= maxVectorSummary {
+		return string(summary)
 	}
 
-	return knownVectors
+	return string(summary[:maxVectorSummary-1]) + "…"
 }
 
 func parseVectorPath(path string) (feature string, vector string) {